@@ -82,11 +82,22 @@ type Configuration struct {
 
 	BucketsResponseTime []float64
 
+	LogMetricsSocket  string
+	BucketsLogMetrics []float64
+
+	ConsistencyCheckSelector  string
+	ConsistencyCheckPeriod    time.Duration
+	ConsistencyCheckThreshold time.Duration
+	HealthzPort               int
+
 	TCPConfigMapName       string
+	HostOwnershipConfigMap string
 	DefaultSSLCertificate  string
 	VerifyHostname         bool
 	DefaultHealthzURL      string
 	StatsCollectProcPeriod time.Duration
+	StatsCollectTCPPeriod  time.Duration
+	WakeUpCheckPeriod      time.Duration
 	PublishService         string
 	Backend                ingress.Controller
 
@@ -225,6 +236,18 @@ func (ic *GenericController) StartAsync() {
 	}
 }
 
+// UpdateService enqueues an update of the status of the ingress resources
+// if svc is the publish service. Changes on other services are ignored.
+func (ic *GenericController) UpdateService(svc *apiv1.Service) {
+	if ic.syncStatus == nil || ic.cfg.PublishService == "" {
+		return
+	}
+	if key := fmt.Sprintf("%s/%s", svc.Namespace, svc.Name); key == ic.cfg.PublishService {
+		glog.V(2).Infof("publish service %v changed, updating ingress status", key)
+		ic.syncStatus.Update()
+	}
+}
+
 // CreateDefaultSSLCertificate ...
 func (ic *GenericController) CreateDefaultSSLCertificate() (path, hash string, crt *x509.Certificate) {
 	defCert, defKey := ssl.GetFakeSSLCert(
@@ -8,7 +8,6 @@ import (
 	"net/http/pprof"
 	"os"
 	"strings"
-	"syscall"
 	"time"
 
 	"github.com/golang/glog"
@@ -83,11 +82,28 @@ func NewIngressController(backend ingress.Controller) *GenericController {
 			`Configures the buckets of the histogram used to compute the response time of the haproxy's admin socket.
 		The response time unit is in seconds.`)
 
+		logMetricsSocket = flags.String("log-metrics-socket", "",
+			`Path to a unix socket used to receive the HTTP logs of haproxy. If configured,
+		the controller parses the logs and exports request duration and status code
+		metrics of every ingress and backend. Default value is empty, which disables
+		log metrics.`)
+
+		bucketsLogMetrics = flags.Float64Slice("buckets-log-metrics",
+			[]float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+			`Configures the buckets of the histogram used to compute the request duration
+		of the log metrics. The request duration unit is in seconds.`)
+
 		publishSvc = flags.String("publish-service", "",
 			`Service fronting the ingress controllers. Takes the form
  		namespace/name. The controller will set the endpoint records on the
  		ingress objects to reflect those on the service.`)
 
+		hostnameOwnershipConfigMap = flags.String("hostname-ownership-configmap", "",
+			`Name and an optional namespace of the ConfigMap used to record the namespace that
+		owns every hostname. Ingress rules of other namespaces declaring an owned hostname are
+		ignored. If a namespace is not provided, the ConfigMap will be created in the same
+		namespace of the controller pod. Leave empty to allow any namespace to declare any hostname`)
+
 		tcpConfigMapName = flags.String("tcp-services-configmap", "",
 			`Name of the ConfigMap that contains the definition of the TCP services to expose.
 		The key in the map indicates the external port to be used. The value is the name of the
@@ -116,6 +132,28 @@ func NewIngressController(backend ingress.Controller) *GenericController {
 		updates Idle_pct every 500ms, which makes that the best configuration value.
 		Change to 0 (zero) to disable this metric.`)
 
+		statsCollectTCPPeriod = flags.Duration("stats-collect-tcp-services-period", 10*time.Second,
+			`Defines the interval between two consecutive readings of the counters of the TCP
+		services. Change to 0 (zero) to disable these metrics.`)
+
+		consistencyCheckSelector = flags.String("consistency-check-selector", "",
+			`Label selector of the controller pods, eg app=haproxy-ingress. If declared, every replica
+		periodically compares its configuration hash with the other replicas. Pods should be in
+		the same namespace of the controller. Leave empty, the default value, to disable the check.`)
+
+		consistencyCheckPeriod = flags.Duration("consistency-check-period", 30*time.Second,
+			`Defines the interval between two consecutive consistency checks of the configuration
+		of the controller replicas.`)
+
+		consistencyCheckThreshold = flags.Duration("consistency-check-threshold", 5*time.Minute,
+			`Defines how long the configuration of two replicas can differ before a warning event
+		is emitted.`)
+
+		wakeUpCheckPeriod = flags.Duration("wake-up-check-period", 1*time.Second,
+			`Defines the interval between two consecutive checks of backends configured with
+		wake-up-deployment or wake-up-webhook that have requests waiting for an endpoint.
+		Change to 0 (zero) to disable the wake up of backends.`)
+
 		profiling = flags.Bool("profiling", true, `Enable profiling via web interface host:port/debug/pprof/`)
 
 		defSSLCertificate = flags.String("default-ssl-certificate", "", `Name of the secret
@@ -292,6 +330,8 @@ func NewIngressController(backend ingress.Controller) *GenericController {
 		AcmeTokenConfigmapName:    *acmeTokenConfigmapName,
 		AcmeTrackTLSAnn:           *acmeTrackTLSAnn,
 		BucketsResponseTime:       *bucketsResponseTime,
+		LogMetricsSocket:          *logMetricsSocket,
+		BucketsLogMetrics:         *bucketsLogMetrics,
 		RateLimitUpdate:           *rateLimitUpdate,
 		ResyncPeriod:              *resyncPeriod,
 		DefaultService:            *defaultSvc,
@@ -299,11 +339,18 @@ func NewIngressController(backend ingress.Controller) *GenericController {
 		WatchNamespace:            *watchNamespace,
 		ConfigMapName:             *configMap,
 		TCPConfigMapName:          *tcpConfigMapName,
+		HostOwnershipConfigMap:    *hostnameOwnershipConfigMap,
 		AnnPrefix:                 *annPrefix,
 		DefaultSSLCertificate:     *defSSLCertificate,
 		VerifyHostname:            *verifyHostname,
 		DefaultHealthzURL:         *defHealthzURL,
 		StatsCollectProcPeriod:    *statsCollectProcPeriod,
+		StatsCollectTCPPeriod:     *statsCollectTCPPeriod,
+		WakeUpCheckPeriod:         *wakeUpCheckPeriod,
+		ConsistencyCheckSelector:  *consistencyCheckSelector,
+		ConsistencyCheckPeriod:    *consistencyCheckPeriod,
+		ConsistencyCheckThreshold: *consistencyCheckThreshold,
+		HealthzPort:               *healthzPort,
 		PublishService:            *publishSvc,
 		Backend:                   backend,
 		ForceNamespaceIsolation:   *forceIsolation,
@@ -321,6 +368,11 @@ func NewIngressController(backend ingress.Controller) *GenericController {
 	return ic
 }
 
+// ConfigHashResponse is the response of the /config/hash endpoint
+type ConfigHashResponse struct {
+	Hash string `json:"hash"`
+}
+
 func registerHandlers(enableProfiling bool, port int, ic *GenericController) {
 	mux := http.NewServeMux()
 	// expose health check endpoint (/healthz)
@@ -353,6 +405,12 @@ func registerHandlers(enableProfiling bool, port int, ic *GenericController) {
 		w.Write([]byte(out))
 	})
 
+	mux.HandleFunc("/config/hash", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		b, _ := json.Marshal(&ConfigHashResponse{Hash: ic.cfg.Backend.ConfigHash()})
+		w.Write(b)
+	})
+
 	mux.HandleFunc("/build", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		b, _ := json.Marshal(ic.Info())
@@ -360,7 +418,7 @@ func registerHandlers(enableProfiling bool, port int, ic *GenericController) {
 	})
 
 	mux.HandleFunc("/stop", func(w http.ResponseWriter, r *http.Request) {
-		err := syscall.Kill(syscall.Getpid(), syscall.SIGTERM)
+		err := stopProcess()
 		if err != nil {
 			glog.Errorf("unexpected error: %v", err)
 		}
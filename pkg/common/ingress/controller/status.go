@@ -51,6 +51,7 @@ const (
 // StatusSync ...
 type StatusSync interface {
 	Run(stopCh <-chan struct{})
+	Update()
 	Shutdown()
 }
 
@@ -73,12 +74,13 @@ type statusSync struct {
 // Run starts the loop to keep the status in sync
 func (s statusSync) Run(stopCh <-chan struct{}) {
 	go s.elector.Run(context.Background())
-	go wait.Forever(s.update, updateInterval)
+	go wait.Forever(s.Update, updateInterval)
 	go s.syncQueue.Run(time.Second, stopCh)
 	<-stopCh
 }
 
-func (s *statusSync) update() {
+// Update enqueues a sync of the status, eg when the publish service changes
+func (s statusSync) Update() {
 	// send a dummy object to the queue to force a sync
 	s.syncQueue.Enqueue("sync status")
 }
@@ -137,7 +139,7 @@ func (s *statusSync) sync(key interface{}) error {
 	if err != nil {
 		return err
 	}
-	if err := s.updateStatus(sliceToStatus(addrs)); err != nil {
+	if err := s.updateStatus(addrs); err != nil {
 		return err
 	}
 
@@ -212,27 +214,17 @@ func NewStatusSyncer(ic *GenericController) StatusSync {
 
 // runningAddresses returns a list of IP addresses and/or FQDN where the
 // ingress controller is currently running
-func (s *statusSync) runningAddresses() ([]string, error) {
+func (s *statusSync) runningAddresses() ([]apiv1.LoadBalancerIngress, error) {
 	if s.ic.cfg.PublishService != "" {
-		ns, name, _ := k8s.ParseNameNS(s.ic.cfg.PublishService)
-		svc, err := s.ic.cfg.Client.CoreV1().Services(ns).Get(name, metav1.GetOptions{})
+		ns, name, err := k8s.ParseNameNS(s.ic.cfg.PublishService)
 		if err != nil {
 			return nil, err
 		}
-
-		addrs := []string{}
-		for _, ip := range svc.Status.LoadBalancer.Ingress {
-			if ip.IP == "" {
-				addrs = append(addrs, ip.Hostname)
-			} else {
-				addrs = append(addrs, ip.IP)
-			}
-		}
-		for _, ip := range svc.Spec.ExternalIPs {
-			addrs = append(addrs, ip)
+		svc, err := s.ic.cfg.Client.CoreV1().Services(ns).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
 		}
-
-		return addrs, nil
+		return serviceToStatus(svc), nil
 	}
 
 	// get information about all the pods running the ingress controller
@@ -250,7 +242,7 @@ func (s *statusSync) runningAddresses() ([]string, error) {
 			addrs = append(addrs, name)
 		}
 	}
-	return addrs, nil
+	return sliceToStatus(addrs), nil
 }
 
 func (s *statusSync) isRunningMultiplePods() bool {
@@ -273,6 +265,37 @@ func stringInSlice(a string, slice []string) bool {
 	return false
 }
 
+// serviceToStatus converts the load balancer status and the external IPs
+// of a service to LoadBalancerIngress. Cloud providers may publish IPs,
+// hostnames (eg AWS ELB) or both, all of them are preserved.
+func serviceToStatus(svc *apiv1.Service) []apiv1.LoadBalancerIngress {
+	lbi := []apiv1.LoadBalancerIngress{}
+	for _, ing := range svc.Status.LoadBalancer.Ingress {
+		if ing.IP == "" && ing.Hostname == "" {
+			continue
+		}
+		if !ingressInSlice(ing, lbi) {
+			lbi = append(lbi, apiv1.LoadBalancerIngress{IP: ing.IP, Hostname: ing.Hostname})
+		}
+	}
+	for _, ip := range svc.Spec.ExternalIPs {
+		ing := apiv1.LoadBalancerIngress{IP: ip}
+		if !ingressInSlice(ing, lbi) {
+			lbi = append(lbi, ing)
+		}
+	}
+	return lbi
+}
+
+func ingressInSlice(a apiv1.LoadBalancerIngress, slice []apiv1.LoadBalancerIngress) bool {
+	for _, b := range slice {
+		if b.IP == a.IP && b.Hostname == a.Hostname {
+			return true
+		}
+	}
+	return false
+}
+
 // sliceToStatus converts a slice of IP and/or hostnames to LoadBalancerIngress
 func sliceToStatus(endpoints []string) []apiv1.LoadBalancerIngress {
 	lbi := []apiv1.LoadBalancerIngress{}
@@ -0,0 +1,195 @@
+/*
+Copyright 2020 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	pool "gopkg.in/go-playground/pool.v3"
+	apiv1 "k8s.io/api/core/v1"
+	extensions "k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestServiceToStatus(t *testing.T) {
+	testCases := []struct {
+		lb          []apiv1.LoadBalancerIngress
+		externalIPs []string
+		expected    []apiv1.LoadBalancerIngress
+	}{
+		// 0
+		{
+			expected: []apiv1.LoadBalancerIngress{},
+		},
+		// 1
+		{
+			lb:       []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}},
+			expected: []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}},
+		},
+		// 2
+		{
+			lb:       []apiv1.LoadBalancerIngress{{Hostname: "lb.aws.local"}},
+			expected: []apiv1.LoadBalancerIngress{{Hostname: "lb.aws.local"}},
+		},
+		// 3
+		{
+			lb:       []apiv1.LoadBalancerIngress{{IP: "10.0.0.1", Hostname: "lb.local"}, {IP: "10.0.0.2"}},
+			expected: []apiv1.LoadBalancerIngress{{IP: "10.0.0.1", Hostname: "lb.local"}, {IP: "10.0.0.2"}},
+		},
+		// 4
+		{
+			externalIPs: []string{"192.168.0.1", "192.168.0.2"},
+			expected:    []apiv1.LoadBalancerIngress{{IP: "192.168.0.1"}, {IP: "192.168.0.2"}},
+		},
+		// 5
+		{
+			lb:          []apiv1.LoadBalancerIngress{{Hostname: "lb.aws.local"}, {}},
+			externalIPs: []string{"192.168.0.1"},
+			expected:    []apiv1.LoadBalancerIngress{{Hostname: "lb.aws.local"}, {IP: "192.168.0.1"}},
+		},
+		// 6
+		{
+			lb:          []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}, {IP: "10.0.0.1"}},
+			externalIPs: []string{"10.0.0.1"},
+			expected:    []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}},
+		},
+	}
+	for i, test := range testCases {
+		svc := &apiv1.Service{}
+		svc.Status.LoadBalancer.Ingress = test.lb
+		svc.Spec.ExternalIPs = test.externalIPs
+		actual := serviceToStatus(svc)
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("status differs on %d -- expected: %+v -- actual: %+v", i, test.expected, actual)
+		}
+	}
+}
+
+func TestRunUpdate(t *testing.T) {
+	testCases := []struct {
+		current  []apiv1.LoadBalancerIngress
+		status   []apiv1.LoadBalancerIngress
+		expected []string
+	}{
+		// 0
+		{
+			current:  []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}},
+			status:   []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}},
+			expected: []string{},
+		},
+		// 1
+		{
+			current:  []apiv1.LoadBalancerIngress{{Hostname: "lb.local"}, {IP: "10.0.0.1"}},
+			status:   []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}, {Hostname: "lb.local"}},
+			expected: []string{},
+		},
+		// 2
+		{
+			current:  []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}},
+			status:   []apiv1.LoadBalancerIngress{{Hostname: "lb.local"}},
+			expected: []string{"get ingresses", "update ingresses/status"},
+		},
+		// 3
+		{
+			status:   []apiv1.LoadBalancerIngress{{IP: "10.0.0.2"}},
+			expected: []string{"get ingresses", "update ingresses/status"},
+		},
+	}
+	for i, test := range testCases {
+		ing := &extensions.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app"},
+		}
+		ing.Status.LoadBalancer.Ingress = test.current
+		client := fake.NewSimpleClientset(ing)
+		statusFunc := func(*extensions.Ingress) []apiv1.LoadBalancerIngress { return nil }
+		p := pool.NewLimited(1)
+		p.Queue(runUpdate(ing, test.status, client, statusFunc)).Wait()
+		p.Close()
+		actions := []string{}
+		for _, action := range client.Actions() {
+			verb := action.GetVerb() + " " + action.GetResource().Resource
+			if sub := action.GetSubresource(); sub != "" {
+				verb += "/" + sub
+			}
+			actions = append(actions, verb)
+		}
+		if !reflect.DeepEqual(actions, test.expected) {
+			t.Errorf("actions differ on %d -- expected: %v -- actual: %v", i, test.expected, actions)
+		}
+	}
+}
+
+type statusSyncMock struct {
+	updates int
+}
+
+func (s *statusSyncMock) Run(stopCh <-chan struct{}) {}
+func (s *statusSyncMock) Update()                    { s.updates++ }
+func (s *statusSyncMock) Shutdown()                  {}
+
+func TestUpdateService(t *testing.T) {
+	testCases := []struct {
+		publishService string
+		namespace      string
+		name           string
+		expected       int
+	}{
+		// 0
+		{
+			publishService: "ingress/haproxy",
+			namespace:      "ingress",
+			name:           "haproxy",
+			expected:       1,
+		},
+		// 1
+		{
+			publishService: "ingress/haproxy",
+			namespace:      "default",
+			name:           "haproxy",
+			expected:       0,
+		},
+		// 2
+		{
+			publishService: "ingress/haproxy",
+			namespace:      "ingress",
+			name:           "app",
+			expected:       0,
+		},
+		// 3
+		{
+			publishService: "",
+			namespace:      "ingress",
+			name:           "haproxy",
+			expected:       0,
+		},
+	}
+	for i, test := range testCases {
+		status := &statusSyncMock{}
+		ic := &GenericController{
+			cfg:        &Configuration{PublishService: test.publishService},
+			syncStatus: status,
+		}
+		ic.UpdateService(&apiv1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: test.namespace, Name: test.name},
+		})
+		if status.updates != test.expected {
+			t.Errorf("status updates differ on %d -- expected: %d -- actual: %d", i, test.expected, status.updates)
+		}
+	}
+}
@@ -45,6 +45,8 @@ type Controller interface {
 	Info() *BackendInfo
 	// AcmeCheck starts a certificate missing/expiring/outdated check
 	AcmeCheck() (int, error)
+	// ConfigHash returns the hash of the last rendered configuration
+	ConfigHash() string
 	// ConfigureFlags allow to configure more flags before the parsing of
 	// command line arguments
 	ConfigureFlags(*pflag.FlagSet)
@@ -25,12 +25,15 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	api "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	k8s "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
 
 	"github.com/jcmoraisjr/haproxy-ingress/pkg/acme"
 	cfile "github.com/jcmoraisjr/haproxy-ingress/pkg/common/file"
@@ -42,12 +45,14 @@ import (
 const dhparamFilename = "dhparam.pem"
 
 type k8scache struct {
-	client                 k8s.Interface
-	listers                *listers
-	controller             *controller.GenericController
-	crossNS                bool
-	acmeSecretKeyName      string
-	acmeTokenConfigmapName string
+	client                  k8s.Interface
+	listers                 *listers
+	controller              *controller.GenericController
+	crossNS                 bool
+	acmeSecretKeyName       string
+	acmeTokenConfigmapName  string
+	hostOwnersConfigmapName string
+	annPrefix               string
 }
 
 func newCache(client k8s.Interface, listers *listers, controller *controller.GenericController) *k8scache {
@@ -70,13 +75,19 @@ func newCache(client k8s.Interface, listers *listers, controller *controller.Gen
 	if !strings.Contains(acmeTokenConfigmapName, "/") {
 		acmeTokenConfigmapName = namespace + "/" + acmeTokenConfigmapName
 	}
+	hostOwnersConfigmapName := cfg.HostOwnershipConfigMap
+	if hostOwnersConfigmapName != "" && !strings.Contains(hostOwnersConfigmapName, "/") {
+		hostOwnersConfigmapName = namespace + "/" + hostOwnersConfigmapName
+	}
 	return &k8scache{
-		client:                 client,
-		listers:                listers,
-		controller:             controller,
-		crossNS:                cfg.AllowCrossNamespace,
-		acmeSecretKeyName:      acmeSecretKeyName,
-		acmeTokenConfigmapName: acmeTokenConfigmapName,
+		client:                  client,
+		listers:                 listers,
+		controller:              controller,
+		crossNS:                 cfg.AllowCrossNamespace,
+		acmeSecretKeyName:       acmeSecretKeyName,
+		acmeTokenConfigmapName:  acmeTokenConfigmapName,
+		hostOwnersConfigmapName: hostOwnersConfigmapName,
+		annPrefix:               cfg.AnnPrefix,
 	}
 }
 
@@ -170,6 +181,31 @@ func (c *k8scache) GetPod(podName string) (*api.Pod, error) {
 	return c.listers.podLister.Pods(namespace).Get(name)
 }
 
+// GetControllerPod returns the pod of the running controller, based on the
+// POD_NAMESPACE and POD_NAME envvars.
+func (c *k8scache) GetControllerPod() (*api.Pod, error) {
+	namespace := os.Getenv("POD_NAMESPACE")
+	podname := os.Getenv("POD_NAME")
+	if namespace == "" || podname == "" {
+		return nil, fmt.Errorf("missing POD_NAMESPACE or POD_NAME envvar")
+	}
+	return c.listers.podLister.Pods(namespace).Get(podname)
+}
+
+// GetControllerPods returns the pods that match the label selector and
+// are in the same namespace of the running controller.
+func (c *k8scache) GetControllerPods(selector string) ([]*api.Pod, error) {
+	namespace := os.Getenv("POD_NAMESPACE")
+	if namespace == "" {
+		return nil, fmt.Errorf("missing POD_NAMESPACE envvar")
+	}
+	l, err := labels.Parse(selector)
+	if err != nil {
+		return nil, err
+	}
+	return c.listers.podLister.Pods(namespace).List(l)
+}
+
 func (c *k8scache) buildSecretName(defaultNamespace, secretName string) (string, string, error) {
 	ns, name, err := cache.SplitMetaNamespaceKey(secretName)
 	if err != nil {
@@ -364,6 +400,94 @@ func (c *k8scache) SetTLSSecretContent(secretName string, pemCrt, pemKey []byte)
 	return c.CreateOrUpdateSecret(secret)
 }
 
+// GetHostOwners returns the namespace that owns every hostname,
+// indexed by the hostname.
+func (c *k8scache) GetHostOwners() (map[string]string, error) {
+	namespace, name, err := cache.SplitMetaNamespaceKey(c.hostOwnersConfigmapName)
+	if err != nil {
+		return nil, err
+	}
+	owners := map[string]string{}
+	config, err := c.listers.configMapLister.ConfigMaps(namespace).Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// no hostname was claimed yet
+			return owners, nil
+		}
+		return nil, err
+	}
+	for key, owner := range config.Data {
+		owners[hostOwnerHostname(key)] = owner
+	}
+	return owners, nil
+}
+
+// SetHostOwners adds new hostname owners to the configmap. Every controller
+// replica claims hostnames, so the configmap is read from the API server and
+// merged: owners already found there are preserved, the first namespace to
+// declare a hostname continues to own it. The update uses the resource version
+// of the read configmap and is retried on conflict.
+func (c *k8scache) SetHostOwners(owners map[string]string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(c.hostOwnersConfigmapName)
+	if err != nil {
+		return err
+	}
+	cli := c.client.CoreV1().ConfigMaps(namespace)
+	conflict := func(err error) bool {
+		// AlreadyExists means that another replica created the configmap in the meantime
+		return errors.IsConflict(err) || errors.IsAlreadyExists(err)
+	}
+	return retry.OnError(retry.DefaultRetry, conflict, func() error {
+		config, err := cli.Get(name, metav1.GetOptions{})
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				return err
+			}
+			config = &api.ConfigMap{}
+			config.Namespace = namespace
+			config.Name = name
+		}
+		if config.Data == nil {
+			config.Data = map[string]string{}
+		}
+		changed := false
+		for hostname, owner := range owners {
+			key := hostOwnerKey(hostname)
+			if _, found := config.Data[key]; !found {
+				config.Data[key] = owner
+				changed = true
+			}
+		}
+		if !changed {
+			return nil
+		}
+		if config.ResourceVersion == "" {
+			_, err = cli.Create(config)
+		} else {
+			_, err = cli.Update(config)
+		}
+		return err
+	})
+}
+
+// hostOwnerKey encodes a hostname as a configmap key. Keys cannot have
+// the `*` of wildcard hostnames, so `*.domain` is stored as `_.domain` --
+// a valid hostname cannot have an underscore.
+func hostOwnerKey(hostname string) string {
+	if strings.HasPrefix(hostname, "*.") {
+		return "_" + hostname[1:]
+	}
+	return hostname
+}
+
+// hostOwnerHostname decodes a configmap key encoded by hostOwnerKey.
+func hostOwnerHostname(key string) string {
+	if strings.HasPrefix(key, "_.") {
+		return "*" + key[1:]
+	}
+	return key
+}
+
 // Implements acme.ServerResolver
 func (c *k8scache) GetToken(domain, uri string) string {
 	config, err := c.GetConfigMap(c.acmeTokenConfigmapName)
@@ -414,6 +538,27 @@ func (c *k8scache) CreateOrUpdateSecret(secret *api.Secret) (err error) {
 	return err
 }
 
+// WakeUpDeployment updates the wake up timestamp annotation of a deployment,
+// which can be watched by external autoscalers, and also scales it to one
+// replica if it's currently scaled to zero.
+func (c *k8scache) WakeUpDeployment(namespace, name string) error {
+	cli := c.client.AppsV1().Deployments(namespace)
+	deployment, err := cli.Get(name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if deployment.Annotations == nil {
+		deployment.Annotations = map[string]string{}
+	}
+	deployment.Annotations[c.annPrefix+"/wake-up-timestamp"] = time.Now().UTC().Format(time.RFC3339)
+	if deployment.Spec.Replicas != nil && *deployment.Spec.Replicas == 0 {
+		replicas := int32(1)
+		deployment.Spec.Replicas = &replicas
+	}
+	_, err = cli.Update(deployment)
+	return err
+}
+
 func (c *k8scache) CreateOrUpdateConfigMap(cm *api.ConfigMap) (err error) {
 	cli := c.client.CoreV1().ConfigMaps(cm.Namespace)
 	if _, err := c.listers.configMapLister.ConfigMaps(cm.Namespace).Get(cm.Name); err != nil {
@@ -0,0 +1,112 @@
+/*
+Copyright 2020 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	api "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+)
+
+func TestSetHostOwners(t *testing.T) {
+	testCases := []struct {
+		current   map[string]string
+		owners    map[string]string
+		conflicts int
+		expected  map[string]string
+	}{
+		// 0
+		{
+			owners:   map[string]string{"d1.local": "ns1"},
+			expected: map[string]string{"d1.local": "ns1"},
+		},
+		// 1
+		{
+			current:  map[string]string{"d1.local": "ns1"},
+			owners:   map[string]string{"d1.local": "ns2", "d2.local": "ns2"},
+			expected: map[string]string{"d1.local": "ns1", "d2.local": "ns2"},
+		},
+		// 2
+		{
+			current:   map[string]string{"d1.local": "ns1"},
+			owners:    map[string]string{"d2.local": "ns2"},
+			conflicts: 2,
+			expected:  map[string]string{"d1.local": "ns1", "d2.local": "ns2"},
+		},
+		// 3
+		{
+			current:  map[string]string{"_.d1.local": "ns1"},
+			owners:   map[string]string{"*.d1.local": "ns2", "*.d2.local": "ns2"},
+			expected: map[string]string{"_.d1.local": "ns1", "_.d2.local": "ns2"},
+		},
+	}
+	for i, test := range testCases {
+		client := fake.NewSimpleClientset()
+		if test.current != nil {
+			client = fake.NewSimpleClientset(&api.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ingress", Name: "owners", ResourceVersion: "1"},
+				Data:       test.current,
+			})
+		}
+		conflicts := test.conflicts
+		client.PrependReactor("update", "configmaps", func(action ktesting.Action) (bool, runtime.Object, error) {
+			if conflicts > 0 {
+				conflicts--
+				return true, nil, errors.NewConflict(schema.GroupResource{Resource: "configmaps"}, "owners", nil)
+			}
+			return false, nil, nil
+		})
+		c := &k8scache{
+			client:                  client,
+			hostOwnersConfigmapName: "ingress/owners",
+		}
+		if err := c.SetHostOwners(test.owners); err != nil {
+			t.Errorf("error updating owners on %d: %v", i, err)
+			continue
+		}
+		config, _ := client.CoreV1().ConfigMaps("ingress").Get("owners", metav1.GetOptions{})
+		if config == nil || !reflect.DeepEqual(config.Data, test.expected) {
+			t.Errorf("owners differ on %d -- expected: %v -- actual: %+v", i, test.expected, config)
+			continue
+		}
+		for key := range config.Data {
+			if errs := validation.IsConfigMapKey(key); len(errs) > 0 {
+				t.Errorf("invalid configmap key on %d: %v", i, errs)
+			}
+		}
+	}
+}
+
+func TestHostOwnerKey(t *testing.T) {
+	for _, hostname := range []string{"d1.local", "*.d1.local", "sub.d1.local"} {
+		key := hostOwnerKey(hostname)
+		if errs := validation.IsConfigMapKey(key); len(errs) > 0 {
+			t.Errorf("invalid configmap key of '%s': %v", hostname, errs)
+		}
+		if actual := hostOwnerHostname(key); actual != hostname {
+			t.Errorf("hostname differs -- expected: %s -- actual: %s", hostname, actual)
+		}
+	}
+}
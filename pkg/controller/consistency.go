@@ -0,0 +1,144 @@
+/*
+Copyright 2020 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	api "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/jcmoraisjr/haproxy-ingress/pkg/common/ingress/controller"
+)
+
+// consistencyCheck compares the hash of the local configuration with the
+// hash of the other controller replicas. Replicas watching the same
+// resources should converge to the same configuration, a divergence that
+// lasts longer than the threshold usually means a stuck informer.
+type consistencyCheck struct {
+	logger    *logger
+	cache     *k8scache
+	metrics   *metrics
+	recorder  record.EventRecorder
+	client    *http.Client
+	hash      func() string
+	selector  string
+	port      int
+	threshold time.Duration
+	// divergedSince has the time, per pod name, the configuration of a
+	// replica started to differ from the local one
+	divergedSince map[string]time.Time
+	reported      map[string]bool
+}
+
+func newConsistencyCheck(hc *HAProxyController) *consistencyCheck {
+	return &consistencyCheck{
+		logger:        hc.logger,
+		cache:         hc.cache,
+		metrics:       hc.metrics,
+		recorder:      hc.recorder,
+		client:        &http.Client{Timeout: 5 * time.Second},
+		hash:          hc.instance.ConfigHash,
+		selector:      hc.cfg.ConsistencyCheckSelector,
+		port:          hc.cfg.HealthzPort,
+		threshold:     hc.cfg.ConsistencyCheckThreshold,
+		divergedSince: map[string]time.Time{},
+		reported:      map[string]bool{},
+	}
+}
+
+func (c *consistencyCheck) Check() {
+	local := c.hash()
+	if local == "" {
+		// HAProxy wasn't configured yet
+		return
+	}
+	self, err := c.cache.GetControllerPod()
+	if err != nil {
+		c.logger.Warn("skipping consistency check: error reading controller pod: %v", err)
+		return
+	}
+	pods, err := c.cache.GetControllerPods(c.selector)
+	if err != nil {
+		c.logger.Warn("skipping consistency check: error reading controller replicas: %v", err)
+		return
+	}
+	now := time.Now()
+	found := make(map[string]bool, len(pods))
+	diverged := 0
+	for _, pod := range pods {
+		if pod.Name == self.Name || pod.Status.PodIP == "" || pod.DeletionTimestamp != nil {
+			continue
+		}
+		hash, err := c.readHash(pod.Status.PodIP)
+		if err != nil {
+			c.logger.Warn("error reading config hash of replica '%s': %v", pod.Name, err)
+			continue
+		}
+		found[pod.Name] = true
+		if hash == "" || hash == local {
+			// empty hash means that the replica wasn't configured yet
+			if c.reported[pod.Name] {
+				c.logger.Info("config of replica '%s' converged", pod.Name)
+			}
+			delete(c.divergedSince, pod.Name)
+			delete(c.reported, pod.Name)
+			continue
+		}
+		since, isDiverged := c.divergedSince[pod.Name]
+		if !isDiverged {
+			c.divergedSince[pod.Name] = now
+			continue
+		}
+		if now.Sub(since) < c.threshold {
+			continue
+		}
+		diverged++
+		if !c.reported[pod.Name] {
+			c.reported[pod.Name] = true
+			c.logger.Warn("config of replica '%s' differs from the local one since %s", pod.Name, since.Format(time.RFC3339))
+			c.recorder.Eventf(self, api.EventTypeWarning, "ConfigDiverged",
+				"config of replica '%s' differs from the local one for more than %s", pod.Name, c.threshold)
+		}
+	}
+	for name := range c.divergedSince {
+		if !found[name] {
+			delete(c.divergedSince, name)
+			delete(c.reported, name)
+		}
+	}
+	c.metrics.SetDivergedReplicas(diverged)
+}
+
+func (c *consistencyCheck) readHash(ip string) (string, error) {
+	resp, err := c.client.Get(fmt.Sprintf("http://%s:%d/config/hash", ip, c.port))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	var hash controller.ConfigHashResponse
+	if err := json.NewDecoder(resp.Body).Decode(&hash); err != nil {
+		return "", err
+	}
+	return hash.Hash, nil
+}
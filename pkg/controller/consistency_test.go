@@ -0,0 +1,137 @@
+/*
+Copyright 2020 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	api "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/jcmoraisjr/haproxy-ingress/pkg/common/ingress/controller"
+)
+
+func TestConsistencyCheck(t *testing.T) {
+	os.Setenv("POD_NAMESPACE", "ingress")
+	os.Setenv("POD_NAME", "haproxy-1")
+	defer os.Unsetenv("POD_NAMESPACE")
+	defer os.Unsetenv("POD_NAME")
+	testCases := []struct {
+		peerHash      string
+		divergedSince time.Duration
+		expDiverged   float64
+		expEvents     []string
+	}{
+		// 0
+		{
+			peerHash:    "abc",
+			expDiverged: 0,
+		},
+		// 1
+		{
+			peerHash:    "",
+			expDiverged: 0,
+		},
+		// 2
+		{
+			peerHash:    "xyz",
+			expDiverged: 0,
+		},
+		// 3
+		{
+			peerHash:      "xyz",
+			divergedSince: 5 * time.Minute,
+			expDiverged:   0,
+		},
+		// 4
+		{
+			peerHash:      "xyz",
+			divergedSince: 15 * time.Minute,
+			expDiverged:   1,
+			expEvents: []string{
+				"Warning ConfigDiverged config of replica 'haproxy-2' differs from the local one for more than 10m0s",
+			},
+		},
+	}
+	for i, test := range testCases {
+		peer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/config/hash" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			b, _ := json.Marshal(&controller.ConfigHashResponse{Hash: test.peerHash})
+			w.Write(b)
+		}))
+		host, portStr, _ := net.SplitHostPort(peer.Listener.Addr().String())
+		port, _ := strconv.Atoi(portStr)
+
+		podInformer := informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0).Core().V1().Pods()
+		for _, name := range []string{"haproxy-1", "haproxy-2"} {
+			podInformer.Informer().GetIndexer().Add(&api.Pod{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ingress", Name: name, Labels: map[string]string{"app": "haproxy"}},
+				Status:     api.PodStatus{PodIP: host},
+			})
+		}
+		recorder := record.NewFakeRecorder(10)
+		gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "config_diverged_replicas"})
+		c := &consistencyCheck{
+			logger:        &logger{},
+			cache:         &k8scache{listers: &listers{podLister: podInformer.Lister()}},
+			metrics:       &metrics{configDivergeGauge: gauge},
+			recorder:      recorder,
+			client:        peer.Client(),
+			hash:          func() string { return "abc" },
+			selector:      "app=haproxy",
+			port:          port,
+			threshold:     10 * time.Minute,
+			divergedSince: map[string]time.Time{},
+			reported:      map[string]bool{},
+		}
+		if test.divergedSince > 0 {
+			c.divergedSince["haproxy-2"] = time.Now().Add(-test.divergedSince)
+		}
+
+		// the event should be emitted only once
+		c.Check()
+		c.Check()
+		peer.Close()
+
+		if actual := testutil.ToFloat64(gauge); actual != test.expDiverged {
+			t.Errorf("diverged replicas differ on %d -- expected: %v -- actual: %v", i, test.expDiverged, actual)
+		}
+		var events []string
+		for len(recorder.Events) > 0 {
+			events = append(events, <-recorder.Events)
+		}
+		if !reflect.DeepEqual(events, test.expEvents) {
+			t.Errorf("events differ on %d -- expected: %v -- actual: %v", i, test.expEvents, events)
+		}
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"os"
 	"sort"
 	"time"
 
@@ -56,6 +57,7 @@ type HAProxyController struct {
 	stopCh            chan struct{}
 	ingressQueue      utils.Queue
 	acmeQueue         utils.Queue
+	wakeUp            *wakeUp
 	leaderelector     types.LeaderElector
 	updateCount       int
 	controller        *controller.GenericController
@@ -65,6 +67,11 @@ type HAProxyController struct {
 	listers           *listers
 	converterOptions  *ingtypes.ConverterOptions
 	reloadStrategy    *string
+	processManager    *string
+	masterSocket      *string
+	masterPidFile     *string
+	adminSocket       *string
+	stateFile         *string
 	maxOldConfigFiles *int
 	validateConfig    *bool
 }
@@ -104,7 +111,7 @@ func (hc *HAProxyController) configController() {
 	hc.stopCh = hc.controller.GetStopCh()
 	hc.controller.SetNewCtrl(hc)
 	hc.logger = &logger{depth: 1}
-	hc.metrics = createMetrics(hc.cfg.BucketsResponseTime)
+	hc.metrics = createMetrics(hc.cfg.BucketsResponseTime, hc.cfg.BucketsLogMetrics)
 	eventBroadcaster := record.NewBroadcaster()
 	eventBroadcaster.StartLogging(hc.logger.Info)
 	watchNamespace := hc.cfg.WatchNamespace
@@ -120,6 +127,7 @@ func (hc *HAProxyController) configController() {
 		hc.cfg.ResyncPeriod)
 	hc.cache = newCache(hc.cfg.Client, hc.listers, hc.controller)
 	hc.ingressQueue = utils.NewRateLimitingQueue(hc.cfg.RateLimitUpdate, hc.syncIngress)
+	hc.wakeUp = newWakeUp(hc.logger, hc.cache)
 	var acmeSigner acme.Signer
 	if hc.cfg.AcmeServer {
 		electorID := fmt.Sprintf("%s-%s", hc.cfg.AcmeElectionID, hc.cfg.IngressClass)
@@ -131,15 +139,28 @@ func (hc *HAProxyController) configController() {
 			acmeSigner.Notify,
 		)
 	}
+	var processManager haproxy.ProcessManager
+	if *hc.processManager == "sidecar" {
+		processManager = haproxy.NewSidecarProcessManager(hc.logger, haproxy.SidecarOptions{
+			HAProxyCmd:   "haproxy",
+			AdminSocket:  *hc.adminSocket,
+			StateFile:    *hc.stateFile,
+			MasterSocket: *hc.masterSocket,
+			PidFile:      *hc.masterPidFile,
+		})
+	}
 	instanceOptions := haproxy.InstanceOptions{
 		HAProxyCmd:        "haproxy",
 		ReloadCmd:         "/haproxy-reload.sh",
 		HAProxyConfigFile: "/etc/haproxy/haproxy.cfg",
 		AcmeSigner:        acmeSigner,
 		AcmeQueue:         hc.acmeQueue,
+		AdminSocket:       *hc.adminSocket,
 		LeaderElector:     hc.leaderelector,
 		Metrics:           hc.metrics,
+		ProcessManager:    processManager,
 		ReloadStrategy:    *hc.reloadStrategy,
+		StateFile:         *hc.stateFile,
 		MaxOldConfigFiles: *hc.maxOldConfigFiles,
 		ValidateConfig:    *hc.validateConfig,
 	}
@@ -155,6 +176,11 @@ func (hc *HAProxyController) configController() {
 		DefaultSSLFile:   hc.createDefaultSSLFile(),
 		FakeCAFile:       hc.createFakeCAFile(),
 		AcmeTrackTLSAnn:  hc.cfg.AcmeTrackTLSAnn,
+		AdminSocket:      *hc.adminSocket,
+		StateFile:        *hc.stateFile,
+		HostOwnership:    hc.cfg.HostOwnershipConfigMap != "",
+		LogMetricsSocket: hc.cfg.LogMetricsSocket,
+		EventRecorder:    hc.recorder,
 	}
 }
 
@@ -166,6 +192,25 @@ func (hc *HAProxyController) startServices() {
 			hc.instance.CalcIdleMetric()
 		}, hc.cfg.StatsCollectProcPeriod, hc.stopCh)
 	}
+	if hc.cfg.StatsCollectTCPPeriod.Milliseconds() > 0 {
+		go wait.Until(func() {
+			hc.instance.CalcTCPServicesMetric()
+		}, hc.cfg.StatsCollectTCPPeriod, hc.stopCh)
+	}
+	if hc.cfg.WakeUpCheckPeriod.Milliseconds() > 0 {
+		go wait.Until(func() {
+			hc.wakeUp.Notify(hc.instance.WakeUpBackends())
+		}, hc.cfg.WakeUpCheckPeriod, hc.stopCh)
+	}
+	if hc.cfg.ConsistencyCheckSelector != "" && hc.cfg.ConsistencyCheckPeriod.Milliseconds() > 0 {
+		check := newConsistencyCheck(hc)
+		go wait.Until(check.Check, hc.cfg.ConsistencyCheckPeriod, hc.stopCh)
+	}
+	if hc.cfg.LogMetricsSocket != "" {
+		if err := newLogMetrics(hc).Listen(hc.stopCh); err != nil {
+			hc.logger.Fatal("error creating the log metrics listener: %v", err)
+		}
+	}
 	if hc.leaderelector != nil {
 		go hc.leaderelector.Run(hc.stopCh)
 	}
@@ -229,6 +274,11 @@ func (hc *HAProxyController) AcmeCheck() (int, error) {
 	return hc.instance.AcmeCheck("external call")
 }
 
+// ConfigHash ...
+func (hc *HAProxyController) ConfigHash() string {
+	return hc.instance.ConfigHash()
+}
+
 // OnStartedLeading ...
 // implements LeaderSubscriber
 func (hc *HAProxyController) OnStartedLeading(ctx context.Context) {
@@ -290,6 +340,12 @@ func (hc *HAProxyController) DeleteSecret(key string) {
 	hc.ingressQueue.Notify()
 }
 
+// UpdateService ...
+// implements ListerEvents
+func (hc *HAProxyController) UpdateService(svc *api.Service) {
+	hc.controller.UpdateService(svc)
+}
+
 // AddConfigMap ...
 // implements ListerEvents
 func (hc *HAProxyController) AddConfigMap(cm *api.ConfigMap) {
@@ -320,6 +376,20 @@ func (hc *HAProxyController) IsValidClass(ing *extensions.Ingress) bool {
 	return hc.controller.IsValidClass(ing)
 }
 
+// IsPeer ...
+// implements ListerEvents
+func (hc *HAProxyController) IsPeer(pod *api.Pod) bool {
+	if hc.configMap == nil {
+		return false
+	}
+	selector := hc.configMap.Data[ingtypes.GlobalPeersSelector]
+	if selector == "" || pod.Namespace != os.Getenv("POD_NAMESPACE") {
+		return false
+	}
+	l, err := labels.Parse(selector)
+	return err == nil && l.Matches(labels.Set(pod.Labels))
+}
+
 // Name provides the complete name of the controller
 func (hc *HAProxyController) Name() string {
 	return "HAProxy Ingress Controller"
@@ -346,6 +416,16 @@ func (hc *HAProxyController) UpdateIngressStatus(*extensions.Ingress) []api.Load
 func (hc *HAProxyController) ConfigureFlags(flags *pflag.FlagSet) {
 	hc.reloadStrategy = flags.String("reload-strategy", "reusesocket",
 		`Name of the reload strategy. Options are: native or reusesocket (default)`)
+	hc.processManager = flags.String("process-manager", "exec",
+		`Defines how HAProxy is started and reloaded. Options are: exec (default), which starts HAProxy in the controller container, or sidecar, which reloads HAProxy running in master-worker mode in another container of the same pod`)
+	hc.masterSocket = flags.String("master-socket", "",
+		`Path of the HAProxy master CLI socket, used by the sidecar process manager to reload HAProxy. If empty, the master process read from --master-pid-file is signaled instead`)
+	hc.masterPidFile = flags.String("master-pid-file", "/var/run/haproxy/haproxy.pid",
+		`Path of the HAProxy master process pid file, used by the sidecar process manager if --master-socket is not declared. The controller and HAProxy containers should share the PID namespace`)
+	hc.adminSocket = flags.String("admin-socket", "/var/run/haproxy-stats.sock",
+		`Path of the HAProxy admin socket, used to read metrics, apply dynamic updates and save the state of the servers before a reload`)
+	hc.stateFile = flags.String("state-file", "/var/lib/haproxy/state-global",
+		`Path of the file where the state of the servers is saved before a reload, used if load-server-state is enabled`)
 	hc.maxOldConfigFiles = flags.Int("max-old-config-files", 0,
 		`Maximum old haproxy timestamped config files to allow before being cleaned up. A value <= 0 indicates a single non-timestamped config file will be used`)
 	hc.validateConfig = flags.Bool("validate-config", false,
@@ -362,6 +442,9 @@ func (hc *HAProxyController) OverrideFlags(flags *pflag.FlagSet) {
 	if !(*hc.reloadStrategy == "native" || *hc.reloadStrategy == "reusesocket" || *hc.reloadStrategy == "multibinder") {
 		glog.Fatalf("Unsupported reload strategy: %v", *hc.reloadStrategy)
 	}
+	if !(*hc.processManager == "exec" || *hc.processManager == "sidecar") {
+		glog.Fatalf("Unsupported process manager: %v", *hc.processManager)
+	}
 }
 
 // SetConfig receives the ConfigMap the user has configured
@@ -44,10 +44,13 @@ type ListerEvents interface {
 	UpdateSecret(key string)
 	DeleteSecret(key string)
 	//
+	UpdateService(svc *api.Service)
+	//
 	AddConfigMap(cm *api.ConfigMap)
 	UpdateConfigMap(cm *api.ConfigMap)
 	//
 	IsValidClass(ing *extensions.Ingress) bool
+	IsPeer(pod *api.Pod) bool
 }
 
 type listers struct {
@@ -217,6 +220,25 @@ func (l *listers) createEndpointLister(informer informersv1.EndpointsInformer) {
 func (l *listers) createServiceLister(informer informersv1.ServiceInformer) {
 	l.serviceLister = informer.Lister()
 	l.serviceInformer = informer.Informer()
+	l.serviceInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			l.events.UpdateService(obj.(*api.Service))
+		},
+		UpdateFunc: func(old, cur interface{}) {
+			oldSvc := old.(*api.Service)
+			curSvc := cur.(*api.Service)
+			if serviceStatusChanged(oldSvc, curSvc) {
+				l.events.UpdateService(curSvc)
+			}
+		},
+	})
+}
+
+// serviceStatusChanged returns true if the addresses used to
+// build the status of the ingress resources have changed
+func serviceStatusChanged(old, cur *api.Service) bool {
+	return !reflect.DeepEqual(old.Status.LoadBalancer, cur.Status.LoadBalancer) ||
+		!reflect.DeepEqual(old.Spec.ExternalIPs, cur.Spec.ExternalIPs)
 }
 
 func (l *listers) createSecretLister(informer informersv1.SecretInformer) {
@@ -276,6 +298,10 @@ func (l *listers) createPodLister(informer informersv1.PodInformer) {
 			curPod := cur.(*api.Pod)
 			if oldPod.DeletionTimestamp != curPod.DeletionTimestamp {
 				l.events.Notify()
+			} else if oldPod.Status.PodIP != curPod.Status.PodIP && l.events.IsPeer(curPod) {
+				// IP changes are used by peers, a pod usually receives its IP
+				// some time after its creation
+				l.events.Notify()
 			}
 		},
 		DeleteFunc: func(obj interface{}) {
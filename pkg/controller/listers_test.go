@@ -0,0 +1,258 @@
+/*
+Copyright 2020 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	api "k8s.io/api/core/v1"
+	extensions "k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+type eventsMock struct {
+	mutex  sync.Mutex
+	events []string
+}
+
+func (e *eventsMock) add(event string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.events = append(e.events, event)
+}
+
+func (e *eventsMock) get() []string {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return append([]string{}, e.events...)
+}
+
+func (e *eventsMock) Notify()                                   { e.add("notify") }
+func (e *eventsMock) UpdateSecret(key string)                   {}
+func (e *eventsMock) DeleteSecret(key string)                   {}
+func (e *eventsMock) AddConfigMap(cm *api.ConfigMap)            {}
+func (e *eventsMock) UpdateConfigMap(cm *api.ConfigMap)         {}
+func (e *eventsMock) IsValidClass(ing *extensions.Ingress) bool { return true }
+func (e *eventsMock) IsPeer(pod *api.Pod) bool                  { return pod.Labels["peer"] == "true" }
+
+func (e *eventsMock) UpdateService(svc *api.Service) {
+	var addrs []string
+	for _, lb := range svc.Status.LoadBalancer.Ingress {
+		addrs = append(addrs, lb.IP+lb.Hostname)
+	}
+	e.add(fmt.Sprintf("service %s/%s %v", svc.Namespace, svc.Name, addrs))
+}
+
+func (e *eventsMock) waitEvents(t *testing.T, count int) []string {
+	var events []string
+	err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		events = e.get()
+		return len(events) >= count, nil
+	})
+	if err != nil {
+		t.Errorf("timeout waiting %d events, received: %v", count, events)
+	}
+	return events
+}
+
+func TestServiceListerEvents(t *testing.T) {
+	events := &eventsMock{}
+	client := fake.NewSimpleClientset()
+	l := &listers{events: events}
+	l.createServiceLister(informers.NewSharedInformerFactory(client, 0).Core().V1().Services())
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go l.serviceInformer.Run(stopCh)
+
+	svcClient := client.CoreV1().Services("ingress")
+	svc := &api.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "ingress", Name: "haproxy"}}
+	svc, _ = svcClient.Create(svc)
+	events.waitEvents(t, 1)
+
+	// labels only, the status didn't change
+	svc = svc.DeepCopy()
+	svc.Labels = map[string]string{"app": "haproxy"}
+	svc, _ = svcClient.Update(svc)
+
+	svc = svc.DeepCopy()
+	svc.Status.LoadBalancer.Ingress = []api.LoadBalancerIngress{{Hostname: "lb.local"}}
+	_, _ = svcClient.UpdateStatus(svc)
+
+	expected := []string{
+		"service ingress/haproxy []",
+		"service ingress/haproxy [lb.local]",
+	}
+	actual := events.waitEvents(t, 2)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("events differ -- expected: %v -- actual: %v", expected, actual)
+	}
+}
+
+func TestPodListerEvents(t *testing.T) {
+	events := &eventsMock{}
+	client := fake.NewSimpleClientset()
+	l := &listers{events: events}
+	l.createPodLister(informers.NewSharedInformerFactory(client, 0).Core().V1().Pods())
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go l.podInformer.Run(stopCh)
+
+	podClient := client.CoreV1().Pods("ingress")
+	app := &api.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ingress", Name: "app"}}
+	app, _ = podClient.Create(app)
+	peer := &api.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ingress", Name: "haproxy", Labels: map[string]string{"peer": "true"}}}
+	peer, _ = podClient.Create(peer)
+	_ = wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		pods, _ := l.podLister.List(labels.Everything())
+		return len(pods) == 2, nil
+	})
+
+	// not a peer, IP changes are ignored
+	app = app.DeepCopy()
+	app.Status.PodIP = "172.17.0.10"
+	_, _ = podClient.UpdateStatus(app)
+
+	peer = peer.DeepCopy()
+	peer.Status.PodIP = "172.17.0.11"
+	_, _ = podClient.UpdateStatus(peer)
+
+	expected := []string{"notify"}
+	actual := events.waitEvents(t, 1)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("events differ -- expected: %v -- actual: %v", expected, actual)
+	}
+}
+
+func TestIsPeer(t *testing.T) {
+	os.Setenv("POD_NAMESPACE", "ingress")
+	defer os.Unsetenv("POD_NAMESPACE")
+	testCases := []struct {
+		selector  string
+		namespace string
+		labels    map[string]string
+		expected  bool
+	}{
+		// 0
+		{
+			selector:  "",
+			namespace: "ingress",
+			labels:    map[string]string{"app": "haproxy"},
+			expected:  false,
+		},
+		// 1
+		{
+			selector:  "app=haproxy",
+			namespace: "ingress",
+			labels:    map[string]string{"app": "haproxy"},
+			expected:  true,
+		},
+		// 2
+		{
+			selector:  "app=haproxy",
+			namespace: "default",
+			labels:    map[string]string{"app": "haproxy"},
+			expected:  false,
+		},
+		// 3
+		{
+			selector:  "app=haproxy",
+			namespace: "ingress",
+			labels:    map[string]string{"app": "web"},
+			expected:  false,
+		},
+		// 4
+		{
+			selector:  "app=(",
+			namespace: "ingress",
+			labels:    map[string]string{"app": "haproxy"},
+			expected:  false,
+		},
+	}
+	for i, test := range testCases {
+		hc := &HAProxyController{
+			configMap: &api.ConfigMap{Data: map[string]string{"peers-selector": test.selector}},
+		}
+		pod := &api.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: test.namespace, Name: "haproxy-1", Labels: test.labels}}
+		if actual := hc.IsPeer(pod); actual != test.expected {
+			t.Errorf("peer differs on %d -- expected: %v -- actual: %v", i, test.expected, actual)
+		}
+	}
+}
+
+func TestServiceStatusChanged(t *testing.T) {
+	testCases := []struct {
+		oldLB    []api.LoadBalancerIngress
+		curLB    []api.LoadBalancerIngress
+		oldIPs   []string
+		curIPs   []string
+		expected bool
+	}{
+		// 0
+		{
+			expected: false,
+		},
+		// 1
+		{
+			oldLB:    []api.LoadBalancerIngress{{IP: "10.0.0.1"}},
+			curLB:    []api.LoadBalancerIngress{{IP: "10.0.0.1"}},
+			expected: false,
+		},
+		// 2
+		{
+			oldLB:    []api.LoadBalancerIngress{{IP: "10.0.0.1"}},
+			curLB:    []api.LoadBalancerIngress{{Hostname: "lb.local"}},
+			expected: true,
+		},
+		// 3
+		{
+			curLB:    []api.LoadBalancerIngress{{IP: "10.0.0.1"}},
+			expected: true,
+		},
+		// 4
+		{
+			oldIPs:   []string{"192.168.0.1"},
+			curIPs:   []string{"192.168.0.1"},
+			expected: false,
+		},
+		// 5
+		{
+			oldIPs:   []string{"192.168.0.1"},
+			curIPs:   []string{"192.168.0.2"},
+			expected: true,
+		},
+	}
+	for i, test := range testCases {
+		old := &api.Service{}
+		old.Status.LoadBalancer.Ingress = test.oldLB
+		old.Spec.ExternalIPs = test.oldIPs
+		cur := &api.Service{}
+		cur.Status.LoadBalancer.Ingress = test.curLB
+		cur.Spec.ExternalIPs = test.curIPs
+		if actual := serviceStatusChanged(old, cur); actual != test.expected {
+			t.Errorf("changed differs on %d -- expected: %v -- actual: %v", i, test.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2020 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"net"
+	"os"
+	"os/user"
+	"strconv"
+)
+
+// logMetrics receives the HTTP logs of haproxy via a datagram unix socket
+// and sends every log line to the haproxy instance, which parses the line
+// and updates the request metrics.
+type logMetrics struct {
+	logger *logger
+	socket string
+	// owner is the user, if it exists, that owns the socket
+	owner  string
+	handle func(line string)
+}
+
+func newLogMetrics(hc *HAProxyController) *logMetrics {
+	return &logMetrics{
+		logger: hc.logger,
+		socket: hc.cfg.LogMetricsSocket,
+		owner:  "haproxy",
+		handle: hc.instance.LogRequest,
+	}
+}
+
+func (l *logMetrics) Listen(stopCh chan struct{}) error {
+	if err := os.Remove(l.socket); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: l.socket, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	if user, err := user.Lookup(l.owner); err == nil {
+		uid, e1 := strconv.Atoi(user.Uid)
+		gid, e2 := strconv.Atoi(user.Gid)
+		if e1 == nil && e2 == nil {
+			if err := os.Chown(l.socket, uid, gid); err != nil {
+				conn.Close()
+				return err
+			}
+			if err := os.Chmod(l.socket, 0600); err != nil {
+				conn.Close()
+				return err
+			}
+		}
+	}
+	l.logger.Info("log metrics: listening on unix socket: %s", l.socket)
+	go l.read(conn)
+	go func() {
+		<-stopCh
+		l.logger.Info("log metrics: closing unix socket")
+		if err := conn.Close(); err != nil {
+			l.logger.Error("log metrics: error closing socket: %v", err)
+		}
+	}()
+	return nil
+}
+
+func (l *logMetrics) read(conn *net.UnixConn) {
+	buf := make([]byte, 65536)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			// closed on shutdown, any other error on a
+			// datagram socket is also unrecoverable
+			return
+		}
+		l.handle(string(buf[:n]))
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2020 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestLogMetricsListen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logmetrics")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "haproxy-log.sock")
+	// stale file of a previous run
+	if err := ioutil.WriteFile(socket, []byte{}, 0644); err != nil {
+		t.Fatalf("error creating stale socket: %v", err)
+	}
+	owner, err := user.Current()
+	if err != nil {
+		t.Fatalf("error reading current user: %v", err)
+	}
+
+	var mutex sync.Mutex
+	var lines []string
+	l := &logMetrics{
+		logger: &logger{},
+		socket: socket,
+		owner:  owner.Username,
+		handle: func(line string) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			lines = append(lines, line)
+		},
+	}
+	stopCh := make(chan struct{})
+	if err := l.Listen(stopCh); err != nil {
+		t.Fatalf("error listening: %v", err)
+	}
+	if stat, err := os.Stat(socket); err != nil {
+		t.Errorf("error reading socket: %v", err)
+	} else if stat.Mode()&os.ModeSocket == 0 || stat.Mode().Perm() != 0600 {
+		t.Errorf("socket mode differs -- expected: socket and 0600 -- actual: %v", stat.Mode())
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("error connecting to socket: %v", err)
+	}
+	expected := []string{
+		`10.0.0.1:40000 [16/Oct/2020:10:00:00.123] _front_http default_app_8080/srv001 0/0/1/10/12 200 512 - - ---- 1/1/0/0/0 0/0 "GET /app HTTP/1.1"`,
+		`10.0.0.1:40000 [16/Oct/2020:10:00:00.123] _front_http default_app_8080/srv001 0/0/1/10/12 404 512 - - ---- 1/1/0/0/0 0/0 "GET / HTTP/1.1"`,
+	}
+	for _, line := range expected {
+		if _, err := conn.Write([]byte(line)); err != nil {
+			t.Errorf("error writing to socket: %v", err)
+		}
+	}
+	conn.Close()
+
+	var actual []string
+	_ = wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		actual = append([]string{}, lines...)
+		return len(actual) >= len(expected), nil
+	})
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("lines differ -- expected: %v -- actual: %v", expected, actual)
+	}
+
+	close(stopCh)
+	err = wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+		if err == nil {
+			conn.Close()
+		}
+		return err != nil, nil
+	})
+	if err != nil {
+		t.Errorf("socket is still listening after stop")
+	}
+}
@@ -32,10 +32,17 @@ type metrics struct {
 	updateSuccessGauge *prometheus.GaugeVec
 	certExpireGauge    *prometheus.GaugeVec
 	certSigningCounter *prometheus.CounterVec
+	tcpSessionsCounter *prometheus.CounterVec
+	tcpBytesInCounter  *prometheus.CounterVec
+	tcpBytesOutCounter *prometheus.CounterVec
+	configHashGauge    *prometheus.GaugeVec
+	configDivergeGauge prometheus.Gauge
+	backendReqTime     *prometheus.HistogramVec
+	backendReqCounter  *prometheus.CounterVec
 	lastTrack          time.Time
 }
 
-func createMetrics(bucketsResponseTime []float64) *metrics {
+func createMetrics(bucketsResponseTime, bucketsLogMetrics []float64) *metrics {
 	namespace := "haproxyingress"
 	metrics := &metrics{
 		responseTime: prometheus.NewHistogramVec(
@@ -103,6 +110,62 @@ func createMetrics(bucketsResponseTime []float64) *metrics {
 			},
 			[]string{"domains", "reason", "success"},
 		),
+		tcpSessionsCounter: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "tcp_service_sessions_total",
+				Help:      "Cumulative number of sessions of a TCP service.",
+			},
+			[]string{"service", "port"},
+		),
+		tcpBytesInCounter: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "tcp_service_bytes_in_total",
+				Help:      "Cumulative number of bytes received from the clients of a TCP service.",
+			},
+			[]string{"service", "port"},
+		),
+		tcpBytesOutCounter: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "tcp_service_bytes_out_total",
+				Help:      "Cumulative number of bytes sent to the clients of a TCP service.",
+			},
+			[]string{"service", "port"},
+		),
+		configHashGauge: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "config_info",
+				Help:      "Hash of the last rendered configuration, as a label. Value is always 1.",
+			},
+			[]string{"hash"},
+		),
+		configDivergeGauge: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "config_diverged_replicas",
+				Help:      "Number of replicas whose configuration differs from the local one for longer than the consistency check threshold.",
+			},
+		),
+		backendReqTime: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "backend_request_duration_seconds",
+				Help:      "Total active time of HTTP requests, read from the haproxy logs.",
+				Buckets:   bucketsLogMetrics,
+			},
+			[]string{"namespace", "ingress", "service", "backend"},
+		),
+		backendReqCounter: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "backend_requests_total",
+				Help:      "Cumulative number of HTTP requests, read from the haproxy logs. Code is the status code class: 1xx to 5xx.",
+			},
+			[]string{"namespace", "ingress", "service", "backend", "code"},
+		),
 	}
 	prometheus.MustRegister(metrics.responseTime)
 	prometheus.MustRegister(metrics.ctlProcTimeSum)
@@ -112,6 +175,13 @@ func createMetrics(bucketsResponseTime []float64) *metrics {
 	prometheus.MustRegister(metrics.updateSuccessGauge)
 	prometheus.MustRegister(metrics.certExpireGauge)
 	prometheus.MustRegister(metrics.certSigningCounter)
+	prometheus.MustRegister(metrics.tcpSessionsCounter)
+	prometheus.MustRegister(metrics.tcpBytesInCounter)
+	prometheus.MustRegister(metrics.tcpBytesOutCounter)
+	prometheus.MustRegister(metrics.configHashGauge)
+	prometheus.MustRegister(metrics.configDivergeGauge)
+	prometheus.MustRegister(metrics.backendReqTime)
+	prometheus.MustRegister(metrics.backendReqCounter)
 	return metrics
 }
 
@@ -123,6 +193,10 @@ func (m *metrics) HAProxySetServerResponseTime(duration time.Duration) {
 	m.responseTime.WithLabelValues("set_server").Observe(duration.Seconds())
 }
 
+func (m *metrics) HAProxyShowStatResponseTime(duration time.Duration) {
+	m.responseTime.WithLabelValues("show_stat").Observe(duration.Seconds())
+}
+
 func (m *metrics) ControllerProcTime(task string, duration time.Duration) {
 	m.ctlProcTimeSum.WithLabelValues(task).Add(duration.Seconds())
 	m.ctlProcCount.WithLabelValues(task).Inc()
@@ -139,6 +213,13 @@ func (m *metrics) AddIdleFactor(idle int) {
 	m.procSecondsCounter.WithLabelValues().Add(float64(100-idle) * totalTime / 100)
 }
 
+func (m *metrics) AddTCPServiceStats(service string, port int, sessions, bytesIn, bytesOut int64) {
+	portStr := strconv.Itoa(port)
+	m.tcpSessionsCounter.WithLabelValues(service, portStr).Add(float64(sessions))
+	m.tcpBytesInCounter.WithLabelValues(service, portStr).Add(float64(bytesIn))
+	m.tcpBytesOutCounter.WithLabelValues(service, portStr).Add(float64(bytesOut))
+}
+
 func (m *metrics) IncUpdateNoop() {
 	m.updatesCounter.WithLabelValues("noop").Inc()
 }
@@ -175,3 +256,23 @@ func (m *metrics) IncCertSigningExpiring(domains string, success bool) {
 func (m *metrics) IncCertSigningOutdated(domains string, success bool) {
 	m.certSigningCounter.WithLabelValues(domains, "outdated", strconv.FormatBool(success)).Inc()
 }
+
+func (m *metrics) SetConfigHash(hash string) {
+	m.configHashGauge.Reset()
+	m.configHashGauge.WithLabelValues(hash).Set(1)
+}
+
+func (m *metrics) SetDivergedReplicas(count int) {
+	m.configDivergeGauge.Set(float64(count))
+}
+
+func (m *metrics) ObserveBackendRequest(namespace, ingress, service, backend string, status int, duration time.Duration) {
+	if duration >= 0 {
+		m.backendReqTime.WithLabelValues(namespace, ingress, service, backend).Observe(duration.Seconds())
+	}
+	code := "unknown"
+	if status >= 100 && status < 600 {
+		code = strconv.Itoa(status/100) + "xx"
+	}
+	m.backendReqCounter.WithLabelValues(namespace, ingress, service, backend, code).Inc()
+}
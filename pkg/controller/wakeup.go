@@ -0,0 +1,99 @@
+/*
+Copyright 2020 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	hatypes "github.com/jcmoraisjr/haproxy-ingress/pkg/haproxy/types"
+)
+
+type wakeUpRequest struct {
+	Namespace string `json:"namespace"`
+	Service   string `json:"service"`
+	Port      string `json:"port"`
+}
+
+type wakeUp struct {
+	logger *logger
+	cache  *k8scache
+	client *http.Client
+	// expire has the time, per backend ID, the last wake up is considered
+	// in progress. Backends aren't woken up again before that time.
+	expire map[string]time.Time
+}
+
+func newWakeUp(logger *logger, cache *k8scache) *wakeUp {
+	return &wakeUp{
+		logger: logger,
+		cache:  cache,
+		client: &http.Client{Timeout: 5 * time.Second},
+		expire: map[string]time.Time{},
+	}
+}
+
+// Notify wakes up the workload of backends with requests waiting for an
+// endpoint. A backend is woken up once per wake up timeout.
+func (w *wakeUp) Notify(backends []*hatypes.Backend) {
+	now := time.Now()
+	for id, expire := range w.expire {
+		if now.After(expire) {
+			delete(w.expire, id)
+		}
+	}
+	for _, backend := range backends {
+		if _, found := w.expire[backend.ID]; found {
+			continue
+		}
+		w.expire[backend.ID] = now.Add(time.Duration(backend.WakeUp.Timeout) * time.Millisecond)
+		w.logger.Info("waking up backend '%s'", backend.ID)
+		if deployment := backend.WakeUp.Deployment; deployment != "" {
+			if err := w.cache.WakeUpDeployment(backend.Namespace, deployment); err != nil {
+				w.logger.Error("error waking up deployment '%s/%s': %v", backend.Namespace, deployment, err)
+			}
+		}
+		if webhook := backend.WakeUp.Webhook; webhook != "" {
+			if err := w.callWebhook(webhook, backend); err != nil {
+				w.logger.Error("error calling wake up webhook of backend '%s': %v", backend.ID, err)
+			}
+		}
+	}
+}
+
+func (w *wakeUp) callWebhook(url string, backend *hatypes.Backend) error {
+	body, err := json.Marshal(&wakeUpRequest{
+		Namespace: backend.Namespace,
+		Service:   backend.Name,
+		Port:      backend.Port,
+	})
+	if err != nil {
+		return err
+	}
+	resp, err := w.client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -53,7 +53,7 @@ var regexValidTime = regexp.MustCompile(`^[0-9]+(us|ms|s|m|h|d)$`)
 func (c *tcpSvcConverter) Sync(tcpservices map[string]string) {
 	// map[key]value is:
 	// - key   => port to expose
-	// - value => <service-name>:<port>:[<PROXY>]:[<PROXY[-<V1|V2>]]:<secret-name-cert>:check-interval:<secret-name-ca>
+	// - value => <service-name>:<port>:[<PROXY>]:[<PROXY[-<V1|V2>]]:<secret-name-cert>:check-interval:<secret-name-ca>:<log>
 	//   - 0: namespace/name of the target service
 	//   - 1: target port number
 	//   - 2: "PROXY" means accept proxy protocol
@@ -61,6 +61,7 @@ func (c *tcpSvcConverter) Sync(tcpservices map[string]string) {
 	//   - 4: namespace/name of crt/key secret if should ssl-offload
 	//   - 5: check interval
 	//   - 6: namespace/name of ca/crl secret if should verify client ssl
+	//   - 7: log format, one of none, tcplog or detailed
 	for k, v := range tcpservices {
 		publicport, err := strconv.Atoi(k)
 		if err != nil {
@@ -115,6 +116,16 @@ func (c *tcpSvcConverter) Sync(tcpservices map[string]string) {
 					checkInterval, publicport, svc.checkInt)
 			}
 		}
+		var logFormat string
+		switch strings.ToLower(svc.log) {
+		case "", "-":
+		case "none", "tcplog", "detailed":
+			logFormat = strings.ToLower(svc.log)
+		default:
+			c.logger.Warn(
+				"using default log format due to an invalid log config on TCP service %d: %s",
+				publicport, svc.log)
+		}
 		servicename := fmt.Sprintf("%s_%s", service.Namespace, service.Name)
 		backend := c.haproxy.AcquireTCPBackend(servicename, publicport)
 		for _, addr := range addrs {
@@ -122,6 +133,7 @@ func (c *tcpSvcConverter) Sync(tcpservices map[string]string) {
 		}
 		backend.ProxyProt.Decode = strings.ToLower(svc.inProxy) == "proxy"
 		backend.CheckInterval = checkInterval
+		backend.LogFormat = logFormat
 		switch strings.ToLower(svc.outProxy) {
 		case "proxy", "proxy-v2":
 			backend.ProxyProt.EncodeVersion = "v2"
@@ -142,12 +154,13 @@ type tcpSvc struct {
 	secretTLS string
 	secretCA  string
 	checkInt  string
+	log       string
 }
 
 func (c *tcpSvcConverter) parseService(service string) *tcpSvc {
-	svc := make([]string, 7)
+	svc := make([]string, 8)
 	for i, v := range strings.Split(service, ":") {
-		if i < 7 {
+		if i < 8 {
 			svc[i] = v
 		}
 	}
@@ -159,5 +172,6 @@ func (c *tcpSvcConverter) parseService(service string) *tcpSvc {
 		secretTLS: svc[4],
 		checkInt:  svc[5],
 		secretCA:  svc[6],
+		log:       svc[7],
 	}
 }
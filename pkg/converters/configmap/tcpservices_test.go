@@ -283,6 +283,54 @@ func TestTCPSvcSync(t *testing.T) {
 				},
 			},
 		},
+		// 19
+		{
+			svcmock:  map[string]string{"default/pg:5432": "172.17.0.101"},
+			services: map[string]string{"5432": "default/pg:5432::::::none"},
+			expected: []*hatypes.TCPBackend{
+				{
+					Name: "default_pg",
+					Port: 5432,
+					Endpoints: []*hatypes.TCPEndpoint{
+						{Name: "srv001", IP: "172.17.0.101", Port: 5432},
+					},
+					CheckInterval: "2s",
+					LogFormat:     "none",
+				},
+			},
+		},
+		// 20
+		{
+			svcmock:  map[string]string{"default/pg:5432": "172.17.0.101"},
+			services: map[string]string{"5432": "default/pg:5432::::::Detailed"},
+			expected: []*hatypes.TCPBackend{
+				{
+					Name: "default_pg",
+					Port: 5432,
+					Endpoints: []*hatypes.TCPEndpoint{
+						{Name: "srv001", IP: "172.17.0.101", Port: 5432},
+					},
+					CheckInterval: "2s",
+					LogFormat:     "detailed",
+				},
+			},
+		},
+		// 21
+		{
+			svcmock:  map[string]string{"default/pg:5432": "172.17.0.101"},
+			services: map[string]string{"5432": "default/pg:5432::::::verbose"},
+			expected: []*hatypes.TCPBackend{
+				{
+					Name: "default_pg",
+					Port: 5432,
+					Endpoints: []*hatypes.TCPEndpoint{
+						{Name: "srv001", IP: "172.17.0.101", Port: 5432},
+					},
+					CheckInterval: "2s",
+				},
+			},
+			logging: `WARN using default log format due to an invalid log config on TCP service 5432: verbose`,
+		},
 	}
 	for i, test := range testCases {
 		c := setup(t)
@@ -0,0 +1,247 @@
+/*
+Copyright 2020 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package harness
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	api "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	convtypes "github.com/jcmoraisjr/haproxy-ingress/pkg/converters/types"
+)
+
+// SecretContent ...
+type SecretContent map[string]map[string][]byte
+
+// Cache is an in-memory implementation of the converters' Cache interface,
+// used to run the converters without a kubernetes cluster. Objects are
+// added either filling the exported fields or using the Add* methods.
+type Cache struct {
+	ControllerPod string
+	HostOwners    map[string]string
+	SvcList       []*api.Service
+	EpList        map[string]*api.Endpoints
+	TermPodList   map[string][]*api.Pod
+	PodList       map[string]*api.Pod
+	SecretTLSPath map[string]string
+	SecretCAPath  map[string]string
+	SecretCRLPath map[string]string
+	SecretDHPath  map[string]string
+	SecretContent SecretContent
+}
+
+// NewCache ...
+func NewCache() *Cache {
+	return &Cache{
+		SvcList:       []*api.Service{},
+		EpList:        map[string]*api.Endpoints{},
+		TermPodList:   map[string][]*api.Pod{},
+		PodList:       map[string]*api.Pod{},
+		SecretTLSPath: map[string]string{},
+		SecretCAPath:  map[string]string{},
+		SecretCRLPath: map[string]string{},
+		SecretDHPath:  map[string]string{},
+		SecretContent: SecretContent{},
+		HostOwners:    map[string]string{},
+	}
+}
+
+// AddService adds a service and its endpoints, ep is optional.
+func (c *Cache) AddService(svc *api.Service, ep *api.Endpoints) {
+	c.SvcList = append(c.SvcList, svc)
+	if ep != nil {
+		c.EpList[svc.Namespace+"/"+svc.Name] = ep
+	}
+}
+
+// AddPod ...
+func (c *Cache) AddPod(pod *api.Pod) {
+	c.PodList[pod.Namespace+"/"+pod.Name] = pod
+}
+
+// AddSecret adds the content of a secret. Secrets with `tls.crt`, `ca.crt`,
+// `ca.crl` or `dhparam.pem` keys are also published as a fake file path,
+// so they can be used as certificates, CA bundles, CRLs or DH params.
+func (c *Cache) AddSecret(secret *api.Secret) {
+	name := secret.Namespace + "/" + secret.Name
+	c.SecretContent[name] = secret.Data
+	path := "/var/haproxy/ssl/" + secret.Namespace + "_" + secret.Name
+	if _, found := secret.Data[api.TLSCertKey]; found {
+		c.SecretTLSPath[name] = path + ".pem"
+	}
+	if _, found := secret.Data["ca.crt"]; found {
+		c.SecretCAPath[name] = path + "_ca.pem"
+	}
+	if _, found := secret.Data["ca.crl"]; found {
+		c.SecretCRLPath[name] = path + "_crl.pem"
+	}
+	if _, found := secret.Data["dhparam.pem"]; found {
+		c.SecretDHPath[name] = path + "_dh.pem"
+	}
+}
+
+func (c *Cache) buildSecretName(defaultNamespace, secretName string) string {
+	if defaultNamespace == "" || strings.Index(secretName, "/") >= 0 {
+		return secretName
+	}
+	return defaultNamespace + "/" + secretName
+}
+
+// GetService ...
+func (c *Cache) GetService(serviceName string) (*api.Service, error) {
+	sname := strings.Split(serviceName, "/")
+	if len(sname) == 2 {
+		for _, svc := range c.SvcList {
+			if svc.Namespace == sname[0] && svc.Name == sname[1] {
+				return svc, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("service not found: '%s'", serviceName)
+}
+
+// GetEndpoints ...
+func (c *Cache) GetEndpoints(service *api.Service) (*api.Endpoints, error) {
+	serviceName := service.Namespace + "/" + service.Name
+	if ep, found := c.EpList[serviceName]; found {
+		return ep, nil
+	}
+	return nil, fmt.Errorf("could not find endpoints for service '%s'", serviceName)
+}
+
+// GetTerminatingPods ...
+func (c *Cache) GetTerminatingPods(service *api.Service) ([]*api.Pod, error) {
+	serviceName := service.Namespace + "/" + service.Name
+	if pods, found := c.TermPodList[serviceName]; found {
+		return pods, nil
+	}
+	return []*api.Pod{}, nil
+}
+
+// GetPod ...
+func (c *Cache) GetPod(podName string) (*api.Pod, error) {
+	if pod, found := c.PodList[podName]; found {
+		return pod, nil
+	}
+	return nil, fmt.Errorf("pod not found: '%s'", podName)
+}
+
+// GetControllerPod ...
+func (c *Cache) GetControllerPod() (*api.Pod, error) {
+	return c.GetPod(c.ControllerPod)
+}
+
+// GetControllerPods ...
+func (c *Cache) GetControllerPods(selector string) ([]*api.Pod, error) {
+	l, err := labels.Parse(selector)
+	if err != nil {
+		return nil, err
+	}
+	namespace := strings.Split(c.ControllerPod, "/")[0]
+	var pods []*api.Pod
+	for _, pod := range c.PodList {
+		if pod.Namespace == namespace && l.Matches(labels.Set(pod.Labels)) {
+			pods = append(pods, pod)
+		}
+	}
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[i].Name < pods[j].Name
+	})
+	return pods, nil
+}
+
+// GetTLSSecretPath ...
+func (c *Cache) GetTLSSecretPath(defaultNamespace, secretName string) (convtypes.CrtFile, error) {
+	fullname := c.buildSecretName(defaultNamespace, secretName)
+	if path, found := c.SecretTLSPath[fullname]; found {
+		return convtypes.CrtFile{
+			Filename:   path,
+			SHA1Hash:   fmt.Sprintf("%x", sha1.Sum([]byte(path))),
+			CommonName: "localhost.localdomain",
+			NotAfter:   time.Now().AddDate(0, 0, 30),
+		}, nil
+	}
+	return convtypes.CrtFile{}, fmt.Errorf("secret not found: '%s'", fullname)
+}
+
+// GetCASecretPath ...
+func (c *Cache) GetCASecretPath(defaultNamespace, secretName string) (ca, crl convtypes.File, err error) {
+	fullname := c.buildSecretName(defaultNamespace, secretName)
+	if path, found := c.SecretCAPath[fullname]; found {
+		ca = convtypes.File{
+			Filename: path,
+			SHA1Hash: fmt.Sprintf("%x", sha1.Sum([]byte(path))),
+		}
+	} else {
+		return ca, crl, fmt.Errorf("secret not found: '%s'", fullname)
+	}
+	if path, found := c.SecretCRLPath[fullname]; found {
+		crl = convtypes.File{
+			Filename: path,
+			SHA1Hash: fmt.Sprintf("%x", sha1.Sum([]byte(path))),
+		}
+	}
+	return ca, crl, nil
+}
+
+// GetDHSecretPath ...
+func (c *Cache) GetDHSecretPath(defaultNamespace, secretName string) (convtypes.File, error) {
+	fullname := c.buildSecretName(defaultNamespace, secretName)
+	if path, found := c.SecretDHPath[fullname]; found {
+		return convtypes.File{
+			Filename: path,
+			SHA1Hash: fmt.Sprintf("%x", sha1.Sum([]byte(path))),
+		}, nil
+	}
+	return convtypes.File{}, fmt.Errorf("secret not found: '%s'", fullname)
+}
+
+// GetSecretContent ...
+func (c *Cache) GetSecretContent(defaultNamespace, secretName, keyName string) ([]byte, error) {
+	fullname := c.buildSecretName(defaultNamespace, secretName)
+	if content, found := c.SecretContent[fullname]; found {
+		if val, found := content[keyName]; found {
+			return val, nil
+		}
+		return nil, fmt.Errorf("secret '%s' does not have file/key '%s'", fullname, keyName)
+	}
+	return nil, fmt.Errorf("secret not found: '%s'", fullname)
+}
+
+// GetHostOwners ...
+func (c *Cache) GetHostOwners() (map[string]string, error) {
+	owners := make(map[string]string, len(c.HostOwners))
+	for hostname, owner := range c.HostOwners {
+		owners[hostname] = owner
+	}
+	return owners, nil
+}
+
+// SetHostOwners ...
+func (c *Cache) SetHostOwners(owners map[string]string) error {
+	for hostname, owner := range owners {
+		if _, found := c.HostOwners[hostname]; !found {
+			c.HostOwners[hostname] = owner
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2020 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package harness
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kylelemons/godebug/diff"
+
+	ingtypes "github.com/jcmoraisjr/haproxy-ingress/pkg/converters/ingress/types"
+	convtypes "github.com/jcmoraisjr/haproxy-ingress/pkg/converters/types"
+	"github.com/jcmoraisjr/haproxy-ingress/pkg/haproxy"
+)
+
+// UpdateGoldenEnv is the name of the environment variable that, if defined
+// as `true`, makes CompareGolden to overwrite golden files instead of
+// comparing them.
+const UpdateGoldenEnv = "UPDATE_GOLDEN"
+
+// Options ...
+type Options struct {
+	// TemplatesDir is the directory with HAProxy Ingress templates,
+	// usually the `rootfs/etc/haproxy` directory of the repository.
+	TemplatesDir string
+}
+
+// Harness renders HAProxy configuration files from the objects added in
+// an in-memory cache. Build a converter using Config() and ConverterOptions(),
+// sync the ingress resources and call Render() to read the final config file.
+type Harness struct {
+	Cache   *Cache
+	Logger  *Logger
+	tempdir string
+	cfgfile string
+	inst    haproxy.Instance
+}
+
+// NewHarness ...
+func NewHarness(options Options) (*Harness, error) {
+	tempdir, err := ioutil.TempDir("", "harness")
+	if err != nil {
+		return nil, fmt.Errorf("error creating tempdir: %v", err)
+	}
+	logger := &Logger{}
+	cfgfile := filepath.Join(tempdir, "haproxy.cfg")
+	inst := haproxy.CreateInstance(logger, haproxy.InstanceOptions{
+		HAProxyConfigFile: cfgfile,
+		MapsDir:           tempdir,
+		Metrics:           &metrics{},
+		TemplatesDir:      options.TemplatesDir,
+	})
+	if err := inst.ParseTemplates(); err != nil {
+		os.RemoveAll(tempdir)
+		return nil, fmt.Errorf("error parsing templates: %v", err)
+	}
+	return &Harness{
+		Cache:   NewCache(),
+		Logger:  logger,
+		tempdir: tempdir,
+		cfgfile: cfgfile,
+		inst:    inst,
+	}, nil
+}
+
+// Teardown removes the temporary files created by the harness.
+func (h *Harness) Teardown() error {
+	return os.RemoveAll(h.tempdir)
+}
+
+// Config returns the HAProxy configuration that should be used by the
+// converters. A new configuration is returned after every Render().
+func (h *Harness) Config() haproxy.Config {
+	return h.inst.Config()
+}
+
+// ConverterOptions returns options of the ingress converter which read
+// objects from the harness' cache and use the default configuration.
+func (h *Harness) ConverterOptions() *ingtypes.ConverterOptions {
+	return &ingtypes.ConverterOptions{
+		Logger:           h.Logger,
+		Cache:            h.Cache,
+		AnnotationPrefix: "ingress.kubernetes.io",
+		DefaultSSLFile: convtypes.CrtFile{
+			Filename: "/var/haproxy/ssl/certs/default.pem",
+			SHA1Hash: "1",
+		},
+		FakeCAFile: convtypes.CrtFile{
+			Filename: "/var/haproxy/ssl/fake-ca.pem",
+			SHA1Hash: "1",
+		},
+	}
+}
+
+// Render writes the current configuration to disk and returns the content
+// of the HAProxy config file. HAProxy isn't reloaded. Paths of map files are
+// normalized to the default maps directory, so the output doesn't depend on
+// the tempdir.
+func (h *Harness) Render() (string, error) {
+	if err := h.inst.WriteConfig(); err != nil {
+		return "", fmt.Errorf("error writing config file: %v", err)
+	}
+	out, err := ioutil.ReadFile(h.cfgfile)
+	if err != nil {
+		return "", fmt.Errorf("error reading config file: %v", err)
+	}
+	return strings.Replace(string(out), h.tempdir, "/etc/haproxy/maps", -1), nil
+}
+
+// CompareGolden compares actual with the content of the golden file, or
+// overwrites the golden file if UpdateGoldenEnv is defined as `true`.
+// The returned error has the differences, if any.
+func (h *Harness) CompareGolden(golden, actual string) error {
+	if os.Getenv(UpdateGoldenEnv) == "true" {
+		if err := ioutil.WriteFile(golden, []byte(actual), 0644); err != nil {
+			return fmt.Errorf("error writing golden file: %v", err)
+		}
+		return nil
+	}
+	expected, err := ioutil.ReadFile(golden)
+	if err != nil {
+		return fmt.Errorf("error reading golden file: %v", err)
+	}
+	if string(expected) != actual {
+		return fmt.Errorf("rendered config differs from %s:\n%s", golden, diff.Diff(string(expected), actual))
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2020 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package harness
+
+import (
+	"testing"
+
+	extensions "k8s.io/api/extensions/v1beta1"
+
+	"github.com/jcmoraisjr/haproxy-ingress/pkg/converters/ingress"
+)
+
+func setup(t testing.TB) *Harness {
+	h, err := NewHarness(Options{TemplatesDir: "../../../rootfs/etc/haproxy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func teardown(t testing.TB, h *Harness) {
+	if err := h.Teardown(); err != nil {
+		t.Errorf("error removing tempdir: %v", err)
+	}
+}
+
+func render(t testing.TB, h *Harness) string {
+	out, err := h.Render()
+	if err != nil {
+		t.Error(err)
+	}
+	return out
+}
+
+func compareGolden(t testing.TB, h *Harness, golden, actual string) {
+	if err := h.CompareGolden(golden, actual); err != nil {
+		t.Error(err)
+	}
+}
+
+func compareLogging(t testing.TB, h *Harness, expected string) {
+	if actual := h.Logger.Logging(); actual != expected {
+		t.Errorf("logging differs -- expected:\n%s\n-- actual:\n%s", expected, actual)
+	}
+}
+
+func TestRender(t *testing.T) {
+	h := setup(t)
+	defer teardown(t, h)
+
+	h.Cache.AddService(CreateService("default/echo", "8080", "172.17.0.11,172.17.0.12"))
+	h.Cache.AddSecret(CreateSecret("default/echo-tls", map[string]string{
+		"tls.crt": "crt",
+		"tls.key": "key",
+	}))
+	ing := CreateIngress("default/echo", "echo.local", "/", "echo:8080")
+	ing.Spec.TLS = []extensions.IngressTLS{{
+		Hosts:      []string{"echo.local"},
+		SecretName: "echo-tls",
+	}}
+	ingress.NewIngressConverter(h.ConverterOptions(), h.Config(), map[string]string{}).Sync([]*extensions.Ingress{ing})
+
+	compareGolden(t, h, "testdata/render.cfg", render(t, h))
+	compareLogging(t, h, "")
+}
+
+func TestRenderMissingService(t *testing.T) {
+	h := setup(t)
+	defer teardown(t, h)
+
+	ing := CreateIngress("default/echo", "echo.local", "/", "echo:8080")
+	ingress.NewIngressConverter(h.ConverterOptions(), h.Config(), map[string]string{}).Sync([]*extensions.Ingress{ing})
+
+	render(t, h)
+	compareLogging(t, h, "WARN skipping backend config of ingress 'default/echo': service not found: 'default/echo'")
+}
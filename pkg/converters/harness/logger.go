@@ -0,0 +1,70 @@
+/*
+Copyright 2020 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package harness
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Logger keeps in memory the messages logged by the converters and
+// the haproxy instance, one line per message prefixed by its level.
+type Logger struct {
+	mutex   sync.Mutex
+	logging []string
+}
+
+// Info ...
+func (l *Logger) Info(msg string, args ...interface{}) {
+	l.log("INFO", msg, args...)
+}
+
+// InfoV ...
+func (l *Logger) InfoV(v int, msg string, args ...interface{}) {
+	l.log(fmt.Sprintf("INFO-V(%d)", v), msg, args...)
+}
+
+// Warn ...
+func (l *Logger) Warn(msg string, args ...interface{}) {
+	l.log("WARN", msg, args...)
+}
+
+// Error ...
+func (l *Logger) Error(msg string, args ...interface{}) {
+	l.log("ERROR", msg, args...)
+}
+
+// Fatal ...
+func (l *Logger) Fatal(msg string, args ...interface{}) {
+	l.log("FATAL", msg, args...)
+}
+
+func (l *Logger) log(level, msg string, args ...interface{}) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.logging = append(l.logging, fmt.Sprintf(level+" "+msg, args...))
+}
+
+// Logging returns the messages logged since the last call, one per line.
+func (l *Logger) Logging() string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	logging := strings.Join(l.logging, "\n")
+	l.logging = nil
+	return logging
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2020 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package harness
+
+import (
+	"time"
+)
+
+// metrics discards everything, the harness doesn't export metrics
+type metrics struct{}
+
+func (m *metrics) HAProxyShowInfoResponseTime(duration time.Duration)                       {}
+func (m *metrics) HAProxySetServerResponseTime(duration time.Duration)                      {}
+func (m *metrics) HAProxyShowStatResponseTime(duration time.Duration)                       {}
+func (m *metrics) ControllerProcTime(task string, duration time.Duration)                   {}
+func (m *metrics) AddIdleFactor(idle int)                                                   {}
+func (m *metrics) AddTCPServiceStats(service string, port int, s, bin, bout int64)          {}
+func (m *metrics) IncUpdateNoop()                                                           {}
+func (m *metrics) IncUpdateDynamic()                                                        {}
+func (m *metrics) IncUpdateFull()                                                           {}
+func (m *metrics) UpdateSuccessful(success bool)                                            {}
+func (m *metrics) SetCertExpireDate(domain, cn string, notAfter *time.Time)                 {}
+func (m *metrics) IncCertSigningMissing(domains string, success bool)                       {}
+func (m *metrics) IncCertSigningExpiring(domains string, success bool)                      {}
+func (m *metrics) IncCertSigningOutdated(domains string, success bool)                      {}
+func (m *metrics) SetConfigHash(hash string)                                                {}
+func (m *metrics) ObserveBackendRequest(ns, ing, svc, back string, st int, d time.Duration) {}
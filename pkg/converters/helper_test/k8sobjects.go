@@ -17,70 +17,18 @@ limitations under the License.
 package helper_test
 
 import (
-	"strings"
-
 	api "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/client-go/kubernetes/scheme"
+
+	"github.com/jcmoraisjr/haproxy-ingress/pkg/converters/harness"
 )
 
 // CreateService ...
 func CreateService(name, port, endpoints string) (*api.Service, *api.Endpoints) {
-	sname := strings.Split(name, "/") // namespace/name of the service
-	sport := strings.Split(port, ":") // numeric-port -or- name:numeric-port -or- name:numeric-port:named-port
-	if len(sport) < 2 {
-		sport = []string{"", port, port}
-	} else if len(sport) < 3 {
-		sport = []string{sport[0], sport[1], sport[1]}
-	}
-
-	svc := CreateObject(`
-apiVersion: v1
-kind: Service
-metadata:
-  name: ` + sname[1] + `
-  namespace: ` + sname[0] + `
-spec:
-  ports:
-  - name: ` + sport[0] + `
-    port: ` + sport[1] + `
-    targetPort: ` + sport[2]).(*api.Service)
-
-	ep := CreateObject(`
-apiVersion: v1
-kind: Endpoints
-metadata:
-  name: ` + sname[1] + `
-  namespace: ` + sname[0] + `
-subsets:
-- addresses: []
-  ports:
-  - name: ` + sport[0] + `
-    port: ` + sport[1] + `
-    protocol: TCP`).(*api.Endpoints)
-
-	addr := []api.EndpointAddress{}
-	for _, e := range strings.Split(endpoints, ",") {
-		if e != "" {
-			target := &api.ObjectReference{
-				Kind:      "Pod",
-				Name:      sname[1] + "-xxxxx",
-				Namespace: sname[0],
-			}
-			addr = append(addr, api.EndpointAddress{IP: e, TargetRef: target})
-		}
-	}
-	ep.Subsets[0].Addresses = addr
-
-	return svc, ep
+	return harness.CreateService(name, port, endpoints)
 }
 
 // CreateObject ...
 func CreateObject(cfg string) runtime.Object {
-	decode := scheme.Codecs.UniversalDeserializer().Decode
-	obj, _, err := decode([]byte(cfg), nil, nil)
-	if err != nil {
-		return nil
-	}
-	return obj
+	return harness.CreateObject(cfg)
 }
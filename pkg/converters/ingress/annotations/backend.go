@@ -18,9 +18,11 @@ package annotations
 
 import (
 	"fmt"
+	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jcmoraisjr/haproxy-ingress/pkg/converters/ingress/types"
 	ingtypes "github.com/jcmoraisjr/haproxy-ingress/pkg/converters/ingress/types"
@@ -29,6 +31,31 @@ import (
 	"github.com/jcmoraisjr/haproxy-ingress/pkg/utils"
 )
 
+func (c *updater) buildBackendAccessLog(d *backData) {
+	accessLog := d.mapper.Get(ingtypes.BackAccessLog)
+	switch accessLog.Value {
+	case "", "all":
+	case "errors":
+		// sampling doesn't apply, errors are always logged
+		d.backend.AccessLog.OnlyErrors = true
+		return
+	case "none":
+		d.backend.AccessLog.Disabled = true
+		return
+	default:
+		c.logger.Warn("ignoring invalid access log mode on %v: %s", accessLog.Source, accessLog.Value)
+	}
+	sample := d.mapper.Get(ingtypes.BackAccessLogSample)
+	if sample.Value == "" {
+		return
+	}
+	if sample.Int() < 1 {
+		c.logger.Warn("ignoring invalid access log sample on %v: %s", sample.Source, sample.Value)
+		return
+	}
+	d.backend.AccessLog.Sample = sample.Int()
+}
+
 func (c *updater) buildBackendAffinity(d *backData) {
 	affinity := d.mapper.Get(ingtypes.BackAffinity)
 	if affinity.Source == nil {
@@ -212,8 +239,8 @@ func (c *updater) buildBackendBlueGreenBalance(d *backData) {
 		deployWeights = append(deployWeights, dw)
 	}
 	for _, ep := range d.backend.Endpoints {
-		if ep.Weight == 0 {
-			// Draining endpoint, remove from blue/green calc
+		if ep.Weight == 0 || ep.NotReady {
+			// Draining or not ready endpoint, remove from blue/green calc
 			continue
 		}
 		hasLabel := false
@@ -672,6 +699,11 @@ func (c *updater) buildBackendProxyProtocol(d *backData) {
 	}
 }
 
+func (c *updater) buildBackendRequestID(d *backData) {
+	d.backend.RequestID.Forward = d.mapper.Get(ingtypes.BackRequestIDForward).Bool()
+	d.backend.RequestID.Response = d.mapper.Get(ingtypes.BackRequestIDResponse).Bool()
+}
+
 var (
 	rewriteURLRegex = regexp.MustCompile(`^[^"' ]*$`)
 )
@@ -809,6 +841,42 @@ func (c *updater) buildBackendWAF(d *backData) {
 	}
 }
 
+func (c *updater) buildBackendWakeUp(d *backData) {
+	deployment := d.mapper.Get(ingtypes.BackWakeUpDeployment)
+	webhook := d.mapper.Get(ingtypes.BackWakeUpWebhook)
+	if deployment.Value == "" && webhook.Value == "" {
+		return
+	}
+	source := deployment.Source
+	if source == nil {
+		source = webhook.Source
+	}
+	if d.backend.ModeTCP {
+		c.logger.Warn("ignoring wake up config on %v: backend is in TCP mode", source)
+		return
+	}
+	if d.backend.Resolver != "" {
+		c.logger.Warn("ignoring wake up config on %v: backend uses DNS resolver '%s'", source, d.backend.Resolver)
+		return
+	}
+	if webhook.Value != "" {
+		u, err := url.Parse(webhook.Value)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			c.logger.Warn("ignoring wake up config on %v: invalid webhook URL: %s", webhook.Source, webhook.Value)
+			return
+		}
+	}
+	timeoutCfg := d.mapper.Get(ingtypes.BackWakeUpTimeout)
+	timeout, err := time.ParseDuration(timeoutCfg.Value)
+	if err != nil || timeout.Milliseconds() <= 0 {
+		c.logger.Warn("ignoring wake up config on %v: invalid timeout: %s", source, timeoutCfg.Value)
+		return
+	}
+	d.backend.WakeUp.Deployment = deployment.Value
+	d.backend.WakeUp.Timeout = int(timeout.Milliseconds())
+	d.backend.WakeUp.Webhook = webhook.Value
+}
+
 func (c *updater) buildBackendWhitelistHTTP(d *backData) {
 	if d.backend.ModeTCP {
 		return
@@ -29,6 +29,79 @@ import (
 	hatypes "github.com/jcmoraisjr/haproxy-ingress/pkg/haproxy/types"
 )
 
+func TestAccessLog(t *testing.T) {
+	testCases := []struct {
+		ann      map[string]string
+		expected hatypes.BackendAccessLogConfig
+		logging  string
+	}{
+		// 0
+		{
+			ann:      map[string]string{},
+			expected: hatypes.BackendAccessLogConfig{},
+		},
+		// 1
+		{
+			ann: map[string]string{
+				ingtypes.BackAccessLog: "all",
+			},
+			expected: hatypes.BackendAccessLogConfig{},
+		},
+		// 2
+		{
+			ann: map[string]string{
+				ingtypes.BackAccessLog: "none",
+			},
+			expected: hatypes.BackendAccessLogConfig{Disabled: true},
+		},
+		// 3
+		{
+			ann: map[string]string{
+				ingtypes.BackAccessLog:       "errors",
+				ingtypes.BackAccessLogSample: "10",
+			},
+			expected: hatypes.BackendAccessLogConfig{OnlyErrors: true},
+		},
+		// 4
+		{
+			ann: map[string]string{
+				ingtypes.BackAccessLogSample: "10",
+			},
+			expected: hatypes.BackendAccessLogConfig{Sample: 10},
+		},
+		// 5
+		{
+			ann: map[string]string{
+				ingtypes.BackAccessLog:       "some",
+				ingtypes.BackAccessLogSample: "10",
+			},
+			expected: hatypes.BackendAccessLogConfig{Sample: 10},
+			logging:  "WARN ignoring invalid access log mode on ingress 'default/ing1': some",
+		},
+		// 6
+		{
+			ann: map[string]string{
+				ingtypes.BackAccessLogSample: "0",
+			},
+			expected: hatypes.BackendAccessLogConfig{},
+			logging:  "WARN ignoring invalid access log sample on ingress 'default/ing1': 0",
+		},
+	}
+	source := &Source{
+		Namespace: "default",
+		Name:      "ing1",
+		Type:      "ingress",
+	}
+	for i, test := range testCases {
+		c := setup(t)
+		d := c.createBackendData("default/app", source, test.ann, map[string]string{})
+		c.createUpdater().buildBackendAccessLog(d)
+		c.compareObjects("access log", i, d.backend.AccessLog, test.expected)
+		c.logger.CompareLogging(test.logging)
+		c.teardown()
+	}
+}
+
 func TestAffinity(t *testing.T) {
 	testCase := []struct {
 		annDefault map[string]string
@@ -1783,6 +1856,103 @@ func TestWAF(t *testing.T) {
 	}
 }
 
+func TestWakeUp(t *testing.T) {
+	testCases := []struct {
+		ann      map[string]string
+		modeTCP  bool
+		resolver string
+		expected hatypes.WakeUpConfig
+		logging  string
+	}{
+		// 0
+		{
+			ann:      map[string]string{},
+			expected: hatypes.WakeUpConfig{},
+		},
+		// 1
+		{
+			ann: map[string]string{
+				ingtypes.BackWakeUpDeployment: "app",
+			},
+			expected: hatypes.WakeUpConfig{Deployment: "app", Timeout: 30000},
+		},
+		// 2
+		{
+			ann: map[string]string{
+				ingtypes.BackWakeUpWebhook: "http://scaler.default:8080/wake",
+				ingtypes.BackWakeUpTimeout: "500ms",
+			},
+			expected: hatypes.WakeUpConfig{Webhook: "http://scaler.default:8080/wake", Timeout: 500},
+		},
+		// 3
+		{
+			ann: map[string]string{
+				ingtypes.BackWakeUpDeployment: "app",
+				ingtypes.BackWakeUpWebhook:    "https://scaler.local/wake",
+				ingtypes.BackWakeUpTimeout:    "1m",
+			},
+			expected: hatypes.WakeUpConfig{Deployment: "app", Webhook: "https://scaler.local/wake", Timeout: 60000},
+		},
+		// 4
+		{
+			ann: map[string]string{
+				ingtypes.BackWakeUpTimeout: "1m",
+			},
+			expected: hatypes.WakeUpConfig{},
+		},
+		// 5
+		{
+			ann: map[string]string{
+				ingtypes.BackWakeUpDeployment: "app",
+				ingtypes.BackWakeUpTimeout:    "30",
+			},
+			expected: hatypes.WakeUpConfig{},
+			logging:  "WARN ignoring wake up config on ingress 'default/ing1': invalid timeout: 30",
+		},
+		// 6
+		{
+			ann: map[string]string{
+				ingtypes.BackWakeUpWebhook: "scaler.local/wake",
+			},
+			expected: hatypes.WakeUpConfig{},
+			logging:  "WARN ignoring wake up config on ingress 'default/ing1': invalid webhook URL: scaler.local/wake",
+		},
+		// 7
+		{
+			ann: map[string]string{
+				ingtypes.BackWakeUpDeployment: "app",
+			},
+			modeTCP:  true,
+			expected: hatypes.WakeUpConfig{},
+			logging:  "WARN ignoring wake up config on ingress 'default/ing1': backend is in TCP mode",
+		},
+		// 8
+		{
+			ann: map[string]string{
+				ingtypes.BackWakeUpDeployment: "app",
+			},
+			resolver: "k8s",
+			expected: hatypes.WakeUpConfig{},
+			logging:  "WARN ignoring wake up config on ingress 'default/ing1': backend uses DNS resolver 'k8s'",
+		},
+	}
+	source := &Source{
+		Namespace: "default",
+		Name:      "ing1",
+		Type:      "ingress",
+	}
+	for i, test := range testCases {
+		c := setup(t)
+		d := c.createBackendData("default/app", source, test.ann, map[string]string{ingtypes.BackWakeUpTimeout: "30s"})
+		d.backend.ModeTCP = test.modeTCP
+		d.backend.Resolver = test.resolver
+		c.createUpdater().buildBackendWakeUp(d)
+		c.compareObjects("wake up", i, d.backend.WakeUp, test.expected)
+		c.logger.CompareLogging(test.logging)
+		c.teardown()
+	}
+}
+
 func TestWhitelistHTTP(t *testing.T) {
 	testCases := []struct {
 		paths    []string
@@ -1923,6 +2093,7 @@ func createBackendPaths(paths ...string) hatypes.BackendPaths {
 			Hostname: testingHostname,
 			Hostpath: testingHostname + path,
 			Path:     path,
+			Match:    hatypes.MatchBegin,
 		})
 	}
 	return hatypes.NewBackendPaths(backendPaths...)
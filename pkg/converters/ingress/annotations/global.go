@@ -19,6 +19,7 @@ package annotations
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -161,6 +162,7 @@ func (c *updater) buildGlobalSyslog(d *globalData) {
 	d.global.Syslog.HTTPLogFormat = d.mapper.Get(ingtypes.GlobalHTTPLogFormat).Value
 	d.global.Syslog.HTTPSLogFormat = d.mapper.Get(ingtypes.GlobalHTTPSLogFormat).Value
 	d.global.Syslog.Length = d.mapper.Get(ingtypes.GlobalSyslogLength).Int()
+	d.global.Syslog.MetricsSocket = c.logMetricsSocket
 	d.global.Syslog.Tag = d.mapper.Get(ingtypes.GlobalSyslogTag).Value
 	d.global.Syslog.TCPLogFormat = d.mapper.Get(ingtypes.GlobalTCPLogFormat).Value
 }
@@ -243,7 +245,7 @@ func (c *updater) buildGlobalDNS(d *globalData) {
 			continue
 		}
 		resolverData := strings.Split(resolver, "=")
-		if len(resolverData) != 2 {
+		if len(resolverData) != 2 || resolverData[0] == "" {
 			c.logger.Warn("ignoring misconfigured resolver: %s", resolver)
 			continue
 		}
@@ -259,6 +261,11 @@ func (c *updater) buildGlobalDNS(d *globalData) {
 			if ns == "" {
 				continue
 			}
+			if ns == "resolv.conf" {
+				// nameservers will also be read from resolv.conf
+				dnsResolver.ParseResolvConf = true
+				continue
+			}
 			if strings.Index(ns, ":") < 0 {
 				// missing port number
 				ns += ":53"
@@ -269,6 +276,10 @@ func (c *updater) buildGlobalDNS(d *globalData) {
 				Endpoint: ns,
 			})
 		}
+		if len(dnsResolver.Nameservers) == 0 && !dnsResolver.ParseResolvConf {
+			c.logger.Warn("ignoring resolver without nameservers: %s", resolver)
+			continue
+		}
 		d.global.DNS.Resolvers = append(d.global.DNS.Resolvers, dnsResolver)
 	}
 	d.global.DNS.ClusterDomain = d.mapper.Get(ingtypes.GlobalDNSClusterDomain).Value
@@ -289,6 +300,76 @@ func (c *updater) buildGlobalForwardFor(d *globalData) {
 	}
 }
 
+func (c *updater) buildGlobalPeers(d *globalData) {
+	selector := d.mapper.Get(ingtypes.GlobalPeersSelector).Value
+	if selector == "" {
+		return
+	}
+	port := d.mapper.Get(ingtypes.GlobalPeersPort).Int()
+	if port <= 0 || port > 65535 {
+		c.logger.Warn("ignoring peers due to invalid port on configmap: '%d'", port)
+		return
+	}
+	local, err := c.cache.GetControllerPod()
+	if err != nil {
+		c.logger.Warn("ignoring peers, cannot find the controller pod: %v", err)
+		return
+	}
+	pods, err := c.cache.GetControllerPods(selector)
+	if err != nil {
+		c.logger.Warn("ignoring peers due to invalid selector on configmap '%s': %v", selector, err)
+		return
+	}
+	var servers []*hatypes.PeerServer
+	var hasLocal bool
+	for _, pod := range pods {
+		if pod.Status.PodIP == "" || pod.DeletionTimestamp != nil {
+			continue
+		}
+		if pod.Name == local.Name {
+			hasLocal = true
+		}
+		servers = append(servers, &hatypes.PeerServer{
+			Name: pod.Name,
+			IP:   pod.Status.PodIP,
+			Port: port,
+		})
+	}
+	if !hasLocal {
+		c.logger.Warn("ignoring peers, controller pod '%s/%s' does not match the selector '%s'", local.Namespace, local.Name, selector)
+		return
+	}
+	// lister doesn't preserve the order, sort to avoid needless reloads
+	sort.Slice(servers, func(i, j int) bool {
+		return servers[i].Name < servers[j].Name
+	})
+	d.global.Peers.LocalPeer = local.Name
+	d.global.Peers.Servers = servers
+}
+
+var (
+	requestIDHeaderRegex = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+)
+
+func (c *updater) buildGlobalRequestID(d *globalData) {
+	if !d.mapper.Get(ingtypes.GlobalRequestID).Bool() {
+		return
+	}
+	header := d.mapper.Get(ingtypes.GlobalRequestIDHeader).Value
+	if !requestIDHeaderRegex.MatchString(header) {
+		c.logger.Warn("ignoring request ID due to invalid header name on configmap: '%s'", header)
+		return
+	}
+	format := d.mapper.Get(ingtypes.GlobalRequestIDFormat).Value
+	if format == "" {
+		c.logger.Warn("ignoring request ID due to missing format on configmap")
+		return
+	}
+	d.global.RequestID.Enabled = true
+	d.global.RequestID.Format = format
+	d.global.RequestID.Header = header
+}
+
 func (c *updater) buildGlobalCustomConfig(d *globalData) {
 	d.global.CustomConfig = utils.LineToSlice(d.mapper.Get(ingtypes.GlobalConfigGlobal).Value)
 	d.global.CustomDefaults = utils.LineToSlice(d.mapper.Get(ingtypes.GlobalConfigDefaults).Value)
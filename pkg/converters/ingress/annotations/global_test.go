@@ -19,6 +19,9 @@ package annotations
 import (
 	"testing"
 
+	api "k8s.io/api/core/v1"
+
+	conv_helper "github.com/jcmoraisjr/haproxy-ingress/pkg/converters/helper_test"
 	ingtypes "github.com/jcmoraisjr/haproxy-ingress/pkg/converters/ingress/types"
 	hatypes "github.com/jcmoraisjr/haproxy-ingress/pkg/haproxy/types"
 )
@@ -212,6 +215,59 @@ k8s3=10.0.1.31:10053,10.0.1.32:10053,10.0.1.33:10053,
 				},
 			},
 		},
+		// 3
+		{
+			config: map[string]string{
+				ingtypes.GlobalDNSResolvers: "k8s=",
+			},
+			logging: `WARN ignoring resolver without nameservers: k8s=`,
+		},
+		// 4
+		{
+			config: map[string]string{
+				ingtypes.GlobalDNSClusterDomain: "cluster.local",
+				ingtypes.GlobalDNSResolvers: `
+k8s1=resolv.conf
+k8s2=10.0.1.21,resolv.conf
+k8s3=10.0.1.31
+`,
+			},
+			expected: hatypes.DNSConfig{
+				ClusterDomain: "cluster.local",
+				Resolvers: []*hatypes.DNSResolver{
+					{
+						Name:            "k8s1",
+						ParseResolvConf: true,
+					},
+					{
+						Name: "k8s2",
+						Nameservers: []*hatypes.DNSNameserver{
+							{
+								Name:     "ns01",
+								Endpoint: "10.0.1.21:53",
+							},
+						},
+						ParseResolvConf: true,
+					},
+					{
+						Name: "k8s3",
+						Nameservers: []*hatypes.DNSNameserver{
+							{
+								Name:     "ns01",
+								Endpoint: "10.0.1.31:53",
+							},
+						},
+					},
+				},
+			},
+		},
+		// 5
+		{
+			config: map[string]string{
+				ingtypes.GlobalDNSResolvers: "=resolv.conf",
+			},
+			logging: `WARN ignoring misconfigured resolver: =resolv.conf`,
+		},
 	}
 	for i, test := range testCases {
 		c := setup(t)
@@ -276,6 +332,196 @@ func TestForwardFor(t *testing.T) {
 	}
 }
 
+func TestRequestID(t *testing.T) {
+	testCases := []struct {
+		conf     map[string]string
+		expected hatypes.RequestIDConfig
+		logging  string
+	}{
+		// 0
+		{
+			conf:     map[string]string{},
+			expected: hatypes.RequestIDConfig{},
+		},
+		// 1
+		{
+			conf: map[string]string{
+				ingtypes.GlobalRequestIDFormat: "%{+X}o%pid%rt",
+				ingtypes.GlobalRequestIDHeader: "X-Request-ID",
+			},
+			expected: hatypes.RequestIDConfig{},
+		},
+		// 2
+		{
+			conf: map[string]string{
+				ingtypes.GlobalRequestID:       "true",
+				ingtypes.GlobalRequestIDFormat: "%{+X}o%pid%rt",
+				ingtypes.GlobalRequestIDHeader: "X-Request-ID",
+			},
+			expected: hatypes.RequestIDConfig{
+				Enabled: true,
+				Format:  "%{+X}o%pid%rt",
+				Header:  "X-Request-ID",
+			},
+		},
+		// 3
+		{
+			conf: map[string]string{
+				ingtypes.GlobalRequestID:       "true",
+				ingtypes.GlobalRequestIDHeader: "X Request",
+			},
+			expected: hatypes.RequestIDConfig{},
+			logging:  "WARN ignoring request ID due to invalid header name on configmap: 'X Request'",
+		},
+		// 4
+		{
+			conf: map[string]string{
+				ingtypes.GlobalRequestID:       "true",
+				ingtypes.GlobalRequestIDHeader: "X-Request-ID",
+			},
+			expected: hatypes.RequestIDConfig{},
+			logging:  "WARN ignoring request ID due to missing format on configmap",
+		},
+	}
+	for i, test := range testCases {
+		c := setup(t)
+		d := c.createGlobalData(test.conf)
+		c.createUpdater().buildGlobalRequestID(d)
+		c.compareObjects("request-id", i, d.global.RequestID, test.expected)
+		c.logger.CompareLogging(test.logging)
+		c.teardown()
+	}
+}
+
+func TestPeers(t *testing.T) {
+	pods := []string{`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: ingress-1
+  namespace: ingress
+  labels:
+    app: ingress
+status:
+  podIP: 10.0.0.11`, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: ingress-2
+  namespace: ingress
+  labels:
+    app: ingress
+status:
+  podIP: 10.0.0.12`, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: ingress-3
+  namespace: ingress
+  labels:
+    app: ingress
+status:
+  podIP: ""`, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: ingress-4
+  namespace: ingress
+  labels:
+    app: other`, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: ingress-5
+  namespace: default
+  labels:
+    app: ingress
+status:
+  podIP: 10.0.0.15`,
+	}
+	testCases := []struct {
+		conf     map[string]string
+		local    string
+		expected hatypes.PeersConfig
+		logging  string
+	}{
+		// 0
+		{
+			conf:     map[string]string{},
+			local:    "ingress/ingress-1",
+			expected: hatypes.PeersConfig{},
+		},
+		// 1
+		{
+			conf: map[string]string{
+				ingtypes.GlobalPeersPort:     "10000",
+				ingtypes.GlobalPeersSelector: "app=ingress",
+			},
+			local: "ingress/ingress-2",
+			expected: hatypes.PeersConfig{
+				LocalPeer: "ingress-2",
+				Servers: []*hatypes.PeerServer{
+					{Name: "ingress-1", IP: "10.0.0.11", Port: 10000},
+					{Name: "ingress-2", IP: "10.0.0.12", Port: 10000},
+				},
+			},
+		},
+		// 2
+		{
+			conf: map[string]string{
+				ingtypes.GlobalPeersPort:     "0",
+				ingtypes.GlobalPeersSelector: "app=ingress",
+			},
+			local:    "ingress/ingress-1",
+			expected: hatypes.PeersConfig{},
+			logging:  "WARN ignoring peers due to invalid port on configmap: '0'",
+		},
+		// 3
+		{
+			conf: map[string]string{
+				ingtypes.GlobalPeersPort:     "10000",
+				ingtypes.GlobalPeersSelector: "app=ingress",
+			},
+			local:    "ingress/ingress-9",
+			expected: hatypes.PeersConfig{},
+			logging:  "WARN ignoring peers, cannot find the controller pod: pod not found: 'ingress/ingress-9'",
+		},
+		// 4
+		{
+			conf: map[string]string{
+				ingtypes.GlobalPeersPort:     "10000",
+				ingtypes.GlobalPeersSelector: "app=ingress",
+			},
+			local:    "ingress/ingress-4",
+			expected: hatypes.PeersConfig{},
+			logging:  "WARN ignoring peers, controller pod 'ingress/ingress-4' does not match the selector 'app=ingress'",
+		},
+		// 5
+		{
+			conf: map[string]string{
+				ingtypes.GlobalPeersPort:     "10000",
+				ingtypes.GlobalPeersSelector: "app in",
+			},
+			local:    "ingress/ingress-1",
+			expected: hatypes.PeersConfig{},
+			logging:  "WARN ignoring peers due to invalid selector on configmap 'app in': unable to parse requirement: found '' expected: '('",
+		},
+	}
+	for i, test := range testCases {
+		c := setup(t)
+		c.cache.PodList = map[string]*api.Pod{}
+		for _, pod := range pods {
+			c.cache.AddPod(conv_helper.CreateObject(pod).(*api.Pod))
+		}
+		c.cache.ControllerPod = test.local
+		d := c.createGlobalData(test.conf)
+		c.createUpdater().buildGlobalPeers(d)
+		c.compareObjects("peers", i, d.global.Peers, test.expected)
+		c.logger.CompareLogging(test.logging)
+		c.teardown()
+	}
+}
+
 func TestFrontingProxy(t *testing.T) {
 	testCases := []struct {
 		ann      map[string]string
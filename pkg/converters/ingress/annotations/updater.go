@@ -38,18 +38,24 @@ type Updater interface {
 // NewUpdater ...
 func NewUpdater(haproxy haproxy.Config, options *ingtypes.ConverterOptions) Updater {
 	return &updater{
-		haproxy: haproxy,
-		logger:  options.Logger,
-		cache:   options.Cache,
-		fakeCA:  options.FakeCAFile,
+		haproxy:          haproxy,
+		logger:           options.Logger,
+		cache:            options.Cache,
+		fakeCA:           options.FakeCAFile,
+		adminSocket:      options.AdminSocket,
+		stateFile:        options.StateFile,
+		logMetricsSocket: options.LogMetricsSocket,
 	}
 }
 
 type updater struct {
-	haproxy haproxy.Config
-	logger  types.Logger
-	cache   convtypes.Cache
-	fakeCA  convtypes.CrtFile
+	haproxy          haproxy.Config
+	logger           types.Logger
+	cache            convtypes.Cache
+	fakeCA           convtypes.CrtFile
+	adminSocket      string
+	stateFile        string
+	logMetricsSocket string
 }
 
 type globalData struct {
@@ -106,7 +112,8 @@ func (c *updater) UpdateGlobalConfig(haproxyConfig haproxy.Config, mapper *Mappe
 		global:   haproxyConfig.Global(),
 		mapper:   mapper,
 	}
-	d.global.AdminSocket = "/var/run/haproxy-stats.sock"
+	d.global.AdminSocket = c.adminSocket
+	d.global.StateFile = c.stateFile
 	d.global.MaxConn = mapper.Get(ingtypes.GlobalMaxConnections).Int()
 	d.global.DrainSupport.Drain = mapper.Get(ingtypes.GlobalDrainSupport).Bool()
 	d.global.DrainSupport.Redispatch = mapper.Get(ingtypes.GlobalDrainSupportRedispatch).Bool()
@@ -125,7 +132,9 @@ func (c *updater) UpdateGlobalConfig(haproxyConfig haproxy.Config, mapper *Mappe
 	c.buildGlobalForwardFor(d)
 	c.buildGlobalHTTPStoHTTP(d)
 	c.buildGlobalModSecurity(d)
+	c.buildGlobalPeers(d)
 	c.buildGlobalProc(d)
+	c.buildGlobalRequestID(d)
 	c.buildGlobalSSL(d)
 	c.buildGlobalStats(d)
 	c.buildGlobalSyslog(d)
@@ -156,6 +165,7 @@ func (c *updater) UpdateBackendConfig(backend *hatypes.Backend, mapper *Mapper)
 	backend.CustomConfig = utils.LineToSlice(mapper.Get(ingtypes.BackConfigBackend).Value)
 	backend.Server.MaxConn = mapper.Get(ingtypes.BackMaxconnServer).Int()
 	backend.Server.MaxQueue = mapper.Get(ingtypes.BackMaxQueueServer).Int()
+	c.buildBackendAccessLog(data)
 	c.buildBackendAffinity(data)
 	c.buildBackendAuthHTTP(data)
 	c.buildBackendBlueGreenBalance(data)
@@ -172,12 +182,14 @@ func (c *updater) UpdateBackendConfig(backend *hatypes.Backend, mapper *Mapper)
 	c.buildBackendOAuth(data)
 	c.buildBackendProtocol(data)
 	c.buildBackendProxyProtocol(data)
+	c.buildBackendRequestID(data)
 	c.buildBackendRewriteURL(data)
 	c.buildBackendServerNaming(data)
 	c.buildBackendSSL(data)
 	c.buildBackendSSLRedirect(data)
 	c.buildBackendTimeout(data)
 	c.buildBackendWAF(data)
+	c.buildBackendWakeUp(data)
 	c.buildBackendWhitelistHTTP(data)
 	c.buildBackendWhitelistTCP(data)
 }
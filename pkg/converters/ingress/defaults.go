@@ -32,6 +32,7 @@ func createDefaults() map[string]string {
 	return map[string]string{
 		types.HostAuthTLSStrict: "false",
 		//
+		types.BackAccessLog:              "all",
 		types.BackBackendServerNaming:    "sequence",
 		types.BackBackendServerSlotsInc:  "1",
 		types.BackSlotsMinFree:           "6",
@@ -47,6 +48,10 @@ func createDefaults() map[string]string {
 		types.BackHSTSMaxAge:             "15768000",
 		types.BackHSTSPreload:            "false",
 		types.BackInitialWeight:          "1",
+		types.BackNotReadyEndpoints:      "ignore",
+		types.BackNotReadyWeight:         "0",
+		types.BackPathType:               "begin",
+		types.BackRequestIDForward:       "true",
 		types.BackSessionCookieDynamic:   "true",
 		types.BackSSLRedirect:            "true",
 		types.BackSSLCipherSuitesBackend: defaultSSLCipherSuites,
@@ -60,6 +65,7 @@ func createDefaults() map[string]string {
 		types.BackTimeoutServerFin:       "50s",
 		types.BackTimeoutTunnel:          "1h",
 		types.BackWAFMode:                "deny",
+		types.BackWakeUpTimeout:          "30s",
 		//
 		types.GlobalAcmeExpiring:                 "30",
 		types.GlobalCookieKey:                    "Ingress",
@@ -82,6 +88,9 @@ func createDefaults() map[string]string {
 		types.GlobalNbprocBalance:                "1",
 		types.GlobalNbthread:                     "2",
 		types.GlobalNoTLSRedirectLocations:       "/.well-known/acme-challenge",
+		types.GlobalPeersPort:                    "10000",
+		types.GlobalRequestIDFormat:              `%{+X}o\ %ci:%cp_%fi:%fp_%Ts_%rt:%pid`,
+		types.GlobalRequestIDHeader:              "X-Request-ID",
 		types.GlobalSSLCiphers:                   defaultSSLCiphers,
 		types.GlobalSSLCipherSuites:              defaultSSLCipherSuites,
 		types.GlobalSSLDHDefaultMaxSize:          "2048",
@@ -26,11 +26,13 @@ import (
 
 	"github.com/jcmoraisjr/haproxy-ingress/pkg/converters/ingress/annotations"
 	ingtypes "github.com/jcmoraisjr/haproxy-ingress/pkg/converters/ingress/types"
+	ingutils "github.com/jcmoraisjr/haproxy-ingress/pkg/converters/ingress/utils"
 	convtypes "github.com/jcmoraisjr/haproxy-ingress/pkg/converters/types"
 	convutils "github.com/jcmoraisjr/haproxy-ingress/pkg/converters/utils"
 	"github.com/jcmoraisjr/haproxy-ingress/pkg/haproxy"
 	hatypes "github.com/jcmoraisjr/haproxy-ingress/pkg/haproxy/types"
 	"github.com/jcmoraisjr/haproxy-ingress/pkg/types"
+	"github.com/jcmoraisjr/haproxy-ingress/pkg/utils"
 )
 
 // Config ...
@@ -43,6 +45,12 @@ func NewIngressConverter(options *ingtypes.ConverterOptions, haproxy haproxy.Con
 	if options.DefaultConfig == nil {
 		options.DefaultConfig = createDefaults
 	}
+	if options.AdminSocket == "" {
+		options.AdminSocket = "/var/run/haproxy-stats.sock"
+	}
+	if options.StateFile == "" {
+		options.StateFile = "/var/lib/haproxy/state-global"
+	}
 	defaultConfig := options.DefaultConfig()
 	for key, value := range globalConfig {
 		defaultConfig[key] = value
@@ -79,13 +87,34 @@ type converter struct {
 	globalConfig       *annotations.Mapper
 	hostAnnotations    map[*hatypes.Host]*annotations.Mapper
 	backendAnnotations map[*hatypes.Backend]*annotations.Mapper
+	hostOwners         map[string]string
+	hostClaims         map[string]string
+	hostRejected       map[string]bool
 }
 
 func (c *converter) Sync(ingress []*extensions.Ingress) {
+	if c.options.HostOwnership {
+		owners, err := c.cache.GetHostOwners()
+		if err != nil {
+			// hostOwners == nil means that hostnames aren't checked in this sync
+			c.logger.Error("error reading hostname owners, ownership will not be checked: %v", err)
+		}
+		c.hostOwners = owners
+		c.hostClaims = map[string]string{}
+		c.hostRejected = map[string]bool{}
+	}
 	for _, ing := range ingress {
 		c.syncIngress(ing)
 	}
 	c.syncAnnotations()
+	if len(c.hostClaims) > 0 {
+		if err := c.cache.SetHostOwners(c.hostClaims); err != nil {
+			c.logger.Error("error updating hostname owners: %v", err)
+		}
+	}
+	if c.hostRejected != nil {
+		c.options.RejectedHosts = c.hostRejected
+	}
 }
 
 func (c *converter) syncIngress(ing *extensions.Ingress) {
@@ -96,6 +125,7 @@ func (c *converter) syncIngress(ing *extensions.Ingress) {
 		Type:      "ingress",
 	}
 	annHost, annBack := c.readAnnotations(ing.Annotations)
+	match := c.readPathType(fullIngName, annBack)
 	if ing.Spec.Backend != nil {
 		svcName, svcPort := readServiceNamePort(ing.Spec.Backend)
 		err := c.addDefaultHostBackend(source, ing.Namespace+"/"+svcName, svcPort, annHost, annBack)
@@ -111,6 +141,9 @@ func (c *converter) syncIngress(ing *extensions.Ingress) {
 		if hostname == "" {
 			hostname = "*"
 		}
+		if !c.acquireHostOwnership(ing, hostname) {
+			continue
+		}
 		host := c.addHost(hostname, source, annHost)
 		for _, path := range rule.HTTP.Paths {
 			uri := path.Path
@@ -128,7 +161,7 @@ func (c *converter) syncIngress(ing *extensions.Ingress) {
 				c.logger.Warn("skipping backend config of ingress '%s': %v", fullIngName, err)
 				continue
 			}
-			host.AddPath(backend, uri)
+			host.AddPathMatch(backend, uri, match).Ingress = fullIngName
 			sslpassthrough, _ := strconv.ParseBool(annHost[ingtypes.HostSSLPassthrough])
 			sslpasshttpport := annHost[ingtypes.HostSSLPassthroughHTTPPort]
 			if sslpassthrough && sslpasshttpport != "" {
@@ -169,8 +202,17 @@ func (c *converter) syncIngress(ing *extensions.Ingress) {
 			tlsAcme = strings.ToLower(annHost[ingtypes.HostCertSigner]) == "acme"
 		}
 		if tlsAcme {
+			var hosts []string
+			for _, tlshost := range tls.Hosts {
+				if c.acquireHostOwnership(ing, tlshost) {
+					hosts = append(hosts, tlshost)
+				}
+			}
+			if len(hosts) == 0 {
+				continue
+			}
 			if tls.SecretName != "" {
-				c.haproxy.AcmeData().AddDomains(ing.Namespace+"/"+tls.SecretName, tls.Hosts)
+				c.haproxy.AcmeData().AddDomains(ing.Namespace+"/"+tls.SecretName, hosts)
 			} else {
 				c.logger.Warn("skipping cert signer of ingress '%s': missing secret name", fullIngName)
 			}
@@ -178,6 +220,38 @@ func (c *converter) syncIngress(ing *extensions.Ingress) {
 	}
 }
 
+// acquireHostOwnership returns true if the namespace of the ingress can use
+// the hostname. The first namespace to declare a hostname owns it, other
+// namespaces declaring the same hostname are rejected.
+func (c *converter) acquireHostOwnership(ing *extensions.Ingress, hostname string) bool {
+	if c.hostOwners == nil || hostname == "*" {
+		return true
+	}
+	owner, found := c.hostOwners[hostname]
+	if !found {
+		c.hostOwners[hostname] = ing.Namespace
+		c.hostClaims[hostname] = ing.Namespace
+		return true
+	}
+	if owner == ing.Namespace {
+		return true
+	}
+	// the same hostname is checked on rules and acme tls
+	rejectedKey := ing.Namespace + "/" + ing.Name + "/" + hostname
+	if c.hostRejected[rejectedKey] {
+		return false
+	}
+	c.hostRejected[rejectedKey] = true
+	c.logger.Warn("skipping hostname '%s' of ingress '%s/%s': hostname is owned by namespace '%s'",
+		hostname, ing.Namespace, ing.Name, owner)
+	// the event is emitted only once, when the hostname is rejected for the first time
+	if c.options.EventRecorder != nil && !c.options.RejectedHosts[rejectedKey] {
+		c.options.EventRecorder.Eventf(ing, api.EventTypeWarning, "HostnameRejected",
+			"hostname '%s' is owned by namespace '%s'", hostname, owner)
+	}
+	return false
+}
+
 func (c *converter) syncAnnotations() {
 	c.updater.UpdateGlobalConfig(c.haproxy, c.globalConfig)
 	for _, host := range c.haproxy.Hosts().Items() {
@@ -270,21 +344,174 @@ func (c *converter) addBackend(source *annotations.Source, hostpath, fullSvcName
 		default:
 			backend.EpNaming = hatypes.EpSequence
 		}
-		if mapper.Get(ingtypes.BackServiceUpstream).Bool() {
-			if addr, err := convutils.CreateSvcEndpoint(svc, port); err == nil {
-				backend.AcquireEndpoint(addr.IP, addr.Port, addr.TargetRef)
-			} else {
-				c.logger.Error("error adding IP of service '%s': %v", fullSvcName, err)
+		if svcWeights := c.readServiceWeights(mapper.Get(ingtypes.BackServiceWeight), svc, svcPort); svcWeights != nil {
+			for _, svcWeight := range svcWeights {
+				first := len(backend.Endpoints)
+				c.addServiceEndpoints(mapper, svcWeight.svc, svcWeight.port, backend)
+				svcWeight.endpoints = backend.Endpoints[first:]
 			}
+			balanceServiceWeights(svcWeights)
 		} else {
-			if err := c.addEndpoints(svc, port, backend); err != nil {
-				c.logger.Error("error adding endpoints of service '%s': %v", fullSvcName, err)
-			}
+			c.addServiceEndpoints(mapper, svc, port, backend)
 		}
 	}
 	return backend, nil
 }
 
+func (c *converter) addServiceEndpoints(mapper *annotations.Mapper, svc *api.Service, port *api.ServicePort, backend *hatypes.Backend) {
+	fullSvcName := svc.Namespace + "/" + svc.Name
+	if mapper.Get(ingtypes.BackServiceUpstream).Bool() {
+		if addr, err := convutils.CreateSvcEndpoint(svc, port); err == nil {
+			backend.AcquireEndpoint(addr.IP, addr.Port, addr.TargetRef)
+		} else {
+			c.logger.Error("error adding IP of service '%s': %v", fullSvcName, err)
+		}
+	} else if svc.Spec.Type == api.ServiceTypeExternalName && mapper.Get(ingtypes.BackUseResolver).Value != "" {
+		// name resolution is made by HAProxy via DNS resolver,
+		// the controller doesn't need to resolve the name itself
+		backend.ExternalName = svc.Spec.ExternalName
+	} else {
+		if err := c.addEndpoints(mapper, svc, port, backend); err != nil {
+			c.logger.Error("error adding endpoints of service '%s': %v", fullSvcName, err)
+		}
+	}
+}
+
+type serviceWeight struct {
+	svc       *api.Service
+	port      *api.ServicePort
+	weight    int
+	endpoints []*hatypes.Endpoint
+}
+
+// readServiceWeights parses a `svcname[:port]=weight,...` list. The port
+// defaults to the port used by the ingress path. A nil slice means that
+// the backend should use the endpoints of its own service only.
+func (c *converter) readServiceWeights(cfg *annotations.ConfigValue, svc *api.Service, svcPort string) []*serviceWeight {
+	if cfg.Value == "" {
+		return nil
+	}
+	var svcWeights []*serviceWeight
+	hasOwnService := false
+	for _, svcWeightCfg := range utils.Split(cfg.Value, ",") {
+		nameWeight := strings.Split(svcWeightCfg, "=")
+		if len(nameWeight) != 2 || nameWeight[0] == "" {
+			c.logger.Warn("ignoring invalid service weight format on %v: %s", cfg.Source, svcWeightCfg)
+			continue
+		}
+		weight, err := strconv.Atoi(nameWeight[1])
+		if err != nil {
+			c.logger.Warn("ignoring invalid service weight value on %v: %s", cfg.Source, svcWeightCfg)
+			continue
+		}
+		if weight < 0 {
+			c.logger.Warn("invalid weight '%d' on %v, using '0' instead", weight, cfg.Source)
+			weight = 0
+		}
+		namePort := strings.Split(nameWeight[0], ":")
+		name := namePort[0]
+		port := svcPort
+		if len(namePort) > 1 {
+			port = namePort[1]
+		}
+		weightSvc := svc
+		if name != svc.Name {
+			weightSvc, err = c.cache.GetService(svc.Namespace + "/" + name)
+			if err != nil {
+				c.logger.Warn("skipping service weight on %v: %v", cfg.Source, err)
+				continue
+			}
+		} else {
+			hasOwnService = true
+		}
+		weightPort := convutils.FindServicePort(weightSvc, port)
+		if weightPort == nil {
+			c.logger.Warn("skipping service weight on %v: port not found on service '%s': '%s'", cfg.Source, name, port)
+			continue
+		}
+		svcWeights = append(svcWeights, &serviceWeight{
+			svc:    weightSvc,
+			port:   weightPort,
+			weight: weight,
+		})
+	}
+	if !hasOwnService {
+		c.logger.Warn("ignoring service weight on %v: service '%s' of the ingress path should be declared", cfg.Source, svc.Name)
+		return nil
+	}
+	return svcWeights
+}
+
+// balanceServiceWeights updates the weight of the endpoints of every service,
+// so the sum of the weights of a service is proportional to its configured
+// weight despite the number of endpoints each service has.
+func balanceServiceWeights(svcWeights []*serviceWeight) {
+	activeEndpoints := func(svcWeight *serviceWeight) (endpoints []*hatypes.Endpoint) {
+		for _, ep := range svcWeight.endpoints {
+			// weight == 0 means a draining endpoint, backup servers are
+			// used only if all the others are down, and not ready endpoints
+			// have their own reduced weight, so none of them should
+			// participate in the balance
+			if ep.Weight > 0 && !ep.Backup && !ep.NotReady {
+				endpoints = append(endpoints, ep)
+			}
+		}
+		return endpoints
+	}
+	lcmCount := 0
+	for _, svcWeight := range svcWeights {
+		svcWeight.endpoints = activeEndpoints(svcWeight)
+		count := len(svcWeight.endpoints)
+		if count == 0 {
+			continue
+		}
+		if lcmCount > 0 {
+			lcmCount = ingutils.LCM(lcmCount, count)
+		} else {
+			lcmCount = count
+		}
+	}
+	if lcmCount == 0 {
+		return
+	}
+	epWeights := make([]int, len(svcWeights))
+	gcdWeight := 0
+	for i, svcWeight := range svcWeights {
+		count := len(svcWeight.endpoints)
+		if count == 0 || svcWeight.weight == 0 {
+			continue
+		}
+		epWeights[i] = svcWeight.weight * lcmCount / count
+		if gcdWeight > 0 {
+			gcdWeight = ingutils.GCD(gcdWeight, epWeights[i])
+		} else {
+			gcdWeight = epWeights[i]
+		}
+	}
+	maxWeight := 0
+	for i := range epWeights {
+		if gcdWeight > 0 {
+			epWeights[i] /= gcdWeight
+		}
+		if epWeights[i] > maxWeight {
+			maxWeight = epWeights[i]
+		}
+	}
+	for i, svcWeight := range svcWeights {
+		weight := epWeights[i]
+		// HAProxy weight must be between 0..256
+		if maxWeight > 256 {
+			weight = weight * 256 / maxWeight
+			if weight == 0 && svcWeight.weight > 0 {
+				weight = 1
+			}
+		}
+		for _, ep := range svcWeight.endpoints {
+			ep.Weight = weight
+		}
+	}
+}
+
 func (c *converter) addTLS(source *annotations.Source, secretName string) convtypes.CrtFile {
 	if secretName != "" {
 		tlsFile, err := c.cache.GetTLSSecretPath(source.Namespace, secretName)
@@ -296,19 +523,42 @@ func (c *converter) addTLS(source *annotations.Source, secretName string) convty
 	return c.options.DefaultSSLFile
 }
 
-func (c *converter) addEndpoints(svc *api.Service, svcPort *api.ServicePort, backend *hatypes.Backend) error {
+func (c *converter) addEndpoints(mapper *annotations.Mapper, svc *api.Service, svcPort *api.ServicePort, backend *hatypes.Backend) error {
 	ready, notReady, err := convutils.CreateEndpoints(c.cache, svc, svcPort)
 	if err != nil {
 		return err
 	}
 	for _, addr := range ready {
-		backend.AcquireEndpoint(addr.IP, addr.Port, addr.TargetRef)
+		acquireEndpoint(backend, addr)
 	}
-	if c.globalConfig.Get(ingtypes.GlobalDrainSupport).Bool() {
+	drainSupport := c.globalConfig.Get(ingtypes.GlobalDrainSupport).Bool()
+	switch notReadyCfg := mapper.Get(ingtypes.BackNotReadyEndpoints); notReadyCfg.Value {
+	case "backup":
 		for _, addr := range notReady {
-			ep := backend.AcquireEndpoint(addr.IP, addr.Port, addr.TargetRef)
-			ep.Weight = 0
+			ep := acquireEndpoint(backend, addr)
+			ep.Backup = true
+			ep.NotReady = true
+		}
+	case "weight":
+		weight := c.readNotReadyWeight(mapper.Get(ingtypes.BackNotReadyWeight))
+		for _, addr := range notReady {
+			ep := acquireEndpoint(backend, addr)
+			ep.Weight = weight
+			ep.NotReady = true
+		}
+	default:
+		if notReadyCfg.Value != "" && notReadyCfg.Value != "ignore" {
+			c.logger.Warn("ignoring invalid not ready endpoints policy on %v: %s", notReadyCfg.Source, notReadyCfg.Value)
+		}
+		if drainSupport {
+			for _, addr := range notReady {
+				ep := acquireEndpoint(backend, addr)
+				ep.Weight = 0
+				ep.NotReady = true
+			}
 		}
+	}
+	if drainSupport {
 		pods, err := c.cache.GetTerminatingPods(svc)
 		if err != nil {
 			return err
@@ -317,6 +567,7 @@ func (c *converter) addEndpoints(svc *api.Service, svcPort *api.ServicePort, bac
 			targetPort := convutils.FindContainerPort(pod, svcPort)
 			if targetPort > 0 {
 				ep := backend.AcquireEndpoint(pod.Status.PodIP, targetPort, pod.Namespace+"/"+pod.Name)
+				ep.UID = string(pod.UID)
 				ep.Weight = 0
 			} else {
 				c.logger.Warn("skipping endpoint %s of service %s/%s: port '%s' was not found",
@@ -327,6 +578,12 @@ func (c *converter) addEndpoints(svc *api.Service, svcPort *api.ServicePort, bac
 	return nil
 }
 
+func acquireEndpoint(backend *hatypes.Backend, addr *convutils.Endpoint) *hatypes.Endpoint {
+	ep := backend.AcquireEndpoint(addr.IP, addr.Port, addr.TargetRef)
+	ep.UID = addr.UID
+	return ep
+}
+
 func (c *converter) readAnnotations(annotations map[string]string) (annHost, annBack map[string]string) {
 	annHost = make(map[string]string, len(annotations))
 	annBack = make(map[string]string, len(annotations))
@@ -344,6 +601,38 @@ func (c *converter) readAnnotations(annotations map[string]string) (annHost, ann
 	return annHost, annBack
 }
 
+// readNotReadyWeight reads the weight of the not ready endpoints,
+// which must be in the range accepted by HAProxy: 0..256
+func (c *converter) readNotReadyWeight(cfg *annotations.ConfigValue) int {
+	weight := cfg.Int()
+	if weight < 0 {
+		c.logger.Warn("invalid not ready weight '%d' on %v, using '0' instead", weight, cfg.Source)
+		weight = 0
+	} else if weight > 256 {
+		c.logger.Warn("invalid not ready weight '%d' on %v, using '256' instead", weight, cfg.Source)
+		weight = 256
+	}
+	return weight
+}
+
+// readPathType reads how the paths of an ingress should be compared
+// with the request path. The path type can be declared as an ingress
+// annotation or as a global default in the configmap.
+func (c *converter) readPathType(fullIngName string, annBack map[string]string) hatypes.MatchType {
+	pathType, found := annBack[ingtypes.BackPathType]
+	if !found {
+		pathType = c.globalConfig.Get(ingtypes.BackPathType).Value
+	}
+	switch match := hatypes.MatchType(strings.ToLower(pathType)); match {
+	case hatypes.MatchBegin, hatypes.MatchExact, hatypes.MatchPrefix:
+		return match
+	case "":
+		return hatypes.MatchBegin
+	}
+	c.logger.Warn("ignoring invalid path type '%s' of ingress '%s', using '%s' instead", pathType, fullIngName, hatypes.MatchBegin)
+	return hatypes.MatchBegin
+}
+
 func readServiceNamePort(backend *extensions.IngressBackend) (string, string) {
 	serviceName := backend.ServiceName
 	servicePort := backend.ServicePort.String()
@@ -17,6 +17,8 @@ limitations under the License.
 package ingress
 
 import (
+	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -29,6 +31,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 
 	conv_helper "github.com/jcmoraisjr/haproxy-ingress/pkg/converters/helper_test"
 	"github.com/jcmoraisjr/haproxy-ingress/pkg/converters/ingress/annotations"
@@ -173,6 +176,155 @@ func TestSyncSvcUpstream(t *testing.T) {
     port: 8080` + defaultBackendConfig)
 }
 
+func TestSyncSvcExternalNameResolver(t *testing.T) {
+	c := setup(t)
+	defer c.teardown()
+
+	svc, _ := c.createSvc1Ann("default/echo", "8080", "", map[string]string{
+		"ingress.kubernetes.io/use-resolver": "k8s",
+	})
+	svc.Spec.Type = api.ServiceTypeExternalName
+	svc.Spec.ExternalName = "echo.domain.local"
+	c.Sync(
+		c.createIng1("default/echo1", "echo1.example.com", "/", "echo:8080"),
+	)
+
+	c.compareConfigBack(`
+- id: default_echo_8080
+  externalname: echo.domain.local` + defaultBackendConfig)
+}
+
+func TestSyncSvcWeight(t *testing.T) {
+	testCases := []struct {
+		svcWeight string
+		expected  string
+		logging   string
+	}{
+		// 0
+		{
+			svcWeight: "echo1=1,echo2=1",
+			expected:  "172.17.1.101:8080=2,172.17.1.201:8080=1,172.17.1.202:8080=1",
+		},
+		// 1
+		{
+			svcWeight: "echo1=80,echo2=20",
+			expected:  "172.17.1.101:8080=8,172.17.1.201:8080=1,172.17.1.202:8080=1",
+		},
+		// 2
+		{
+			svcWeight: "echo1=100,echo2=0",
+			expected:  "172.17.1.101:8080=1,172.17.1.201:8080=0,172.17.1.202:8080=0",
+		},
+		// 3
+		{
+			svcWeight: "echo1=1000,echo2=1",
+			expected:  "172.17.1.101:8080=256,172.17.1.201:8080=1,172.17.1.202:8080=1",
+		},
+		// 4
+		{
+			svcWeight: "echo1=50, echo2:http=50",
+			expected:  "172.17.1.101:8080=2,172.17.1.201:8080=1,172.17.1.202:8080=1",
+		},
+		// 5
+		{
+			svcWeight: "echo1=1,echo2=-1",
+			expected:  "172.17.1.101:8080=1,172.17.1.201:8080=0,172.17.1.202:8080=0",
+			logging:   `WARN invalid weight '-1' on ingress 'default/echo', using '0' instead`,
+		},
+		// 6
+		{
+			svcWeight: "echo1=1,echo3=1",
+			expected:  "172.17.1.101:8080=1",
+			logging:   `WARN skipping service weight on ingress 'default/echo': service not found: 'default/echo3'`,
+		},
+		// 7
+		{
+			svcWeight: "echo1=1,echo2:9000=1",
+			expected:  "172.17.1.101:8080=1",
+			logging:   `WARN skipping service weight on ingress 'default/echo': port not found on service 'echo2': '9000'`,
+		},
+		// 8
+		{
+			svcWeight: "echo1,echo2=x",
+			expected:  "172.17.1.101:8080=100",
+			logging: `
+WARN ignoring invalid service weight format on ingress 'default/echo': echo1
+WARN ignoring invalid service weight value on ingress 'default/echo': echo2=x
+WARN ignoring service weight on ingress 'default/echo': service 'echo1' of the ingress path should be declared`,
+		},
+		// 9
+		{
+			svcWeight: "echo2=1",
+			expected:  "172.17.1.101:8080=100",
+			logging:   `WARN ignoring service weight on ingress 'default/echo': service 'echo1' of the ingress path should be declared`,
+		},
+	}
+	for i, test := range testCases {
+		c := setup(t)
+		c.createSvc1("default/echo1", "http:8080:8080", "172.17.1.101")
+		c.createSvc1("default/echo2", "http:8080:8080", "172.17.1.201,172.17.1.202")
+		c.Sync(c.createIng1Ann("default/echo", "echo.example.com", "/", "echo1:8080", map[string]string{
+			"ingress.kubernetes.io/service-weight": test.svcWeight,
+		}))
+		backend := c.hconfig.Backends().FindBackend("default", "echo1", "8080")
+		var endpoints []string
+		for _, ep := range backend.Endpoints {
+			endpoints = append(endpoints, fmt.Sprintf("%s:%d=%d", ep.IP, ep.Port, ep.Weight))
+		}
+		actual := strings.Join(endpoints, ",")
+		if actual != test.expected {
+			t.Errorf("endpoints differ on %d: expected '%s' but was '%s'", i, test.expected, actual)
+		}
+		c.logger.CompareLogging(test.logging)
+		c.teardown()
+	}
+}
+
+func TestSyncSvcWeightNotReady(t *testing.T) {
+	testCases := []struct {
+		ann      map[string]string
+		expected string
+	}{
+		// 0
+		{
+			expected: "172.17.1.101:8080=1,172.17.1.201:8080=1,172.17.1.202:8080=0",
+		},
+		// 1
+		{
+			ann:      map[string]string{"not-ready-weight": "5"},
+			expected: "172.17.1.101:8080=1,172.17.1.201:8080=1,172.17.1.202:8080=5",
+		},
+	}
+	for i, test := range testCases {
+		c := setup(t)
+		c.createSvc1("default/echo1", "http:8080:8080", "172.17.1.101")
+		_, ep := c.createSvc1("default/echo2", "http:8080:8080", "172.17.1.201,172.17.1.202")
+		ss := &ep.Subsets[0]
+		addr := ss.Addresses
+		ss.Addresses = []api.EndpointAddress{addr[0]}
+		ss.NotReadyAddresses = []api.EndpointAddress{addr[1]}
+		ann := map[string]string{
+			"ingress.kubernetes.io/service-weight":      "echo1=1,echo2=1",
+			"ingress.kubernetes.io/not-ready-endpoints": "weight",
+		}
+		for key, value := range test.ann {
+			ann["ingress.kubernetes.io/"+key] = value
+		}
+		c.Sync(c.createIng1Ann("default/echo", "echo.example.com", "/", "echo1:8080", ann))
+		backend := c.hconfig.Backends().FindBackend("default", "echo1", "8080")
+		var endpoints []string
+		for _, ep := range backend.Endpoints {
+			endpoints = append(endpoints, fmt.Sprintf("%s:%d=%d", ep.IP, ep.Port, ep.Weight))
+		}
+		actual := strings.Join(endpoints, ",")
+		if actual != test.expected {
+			t.Errorf("endpoints differ on %d: expected '%s' but was '%s'", i, test.expected, actual)
+		}
+		c.logger.CompareLogging("")
+		c.teardown()
+	}
+}
+
 func TestSyncSingle(t *testing.T) {
 	c := setup(t)
 	defer c.teardown()
@@ -234,6 +386,101 @@ func TestSyncReuseHost(t *testing.T) {
     backend: default_echo1_8080`)
 }
 
+func TestSyncHostOwnership(t *testing.T) {
+	c := setup(t)
+	defer c.teardown()
+
+	c.hostOwnership = true
+	c.cache.HostOwners = map[string]string{
+		"echo2.example.com": "ns2",
+	}
+	c.createSvc1("ns1/echo", "8080", "172.17.0.11")
+	c.createSvc1("ns2/echo", "8080", "172.17.0.12")
+	c.Sync(
+		c.createIng1("ns1/echo", "echo1.example.com", "/", "echo:8080"),
+		c.createIng1("ns1/echo2", "echo2.example.com", "/app", "echo:8080"),
+		c.createIng1("ns2/echo", "echo1.example.com", "/path", "echo:8080"),
+		c.createIng1("ns2/echo2", "echo2.example.com", "/", "echo:8080"),
+		c.createIng1("ns2/echo3", "", "/", "echo:8080"),
+	)
+
+	c.compareConfigFront(`
+- hostname: echo1.example.com
+  paths:
+  - path: /
+    backend: ns1_echo_8080
+- hostname: echo2.example.com
+  paths:
+  - path: /
+    backend: ns2_echo_8080`)
+
+	c.compareConfigDefaultFront(`
+hostname: '*'
+paths:
+- path: /
+  backend: ns2_echo_8080`)
+
+	expOwners := map[string]string{
+		"echo1.example.com": "ns1",
+		"echo2.example.com": "ns2",
+	}
+	if !reflect.DeepEqual(c.cache.HostOwners, expOwners) {
+		t.Errorf("host owners differ, expected: %v, actual: %v", expOwners, c.cache.HostOwners)
+	}
+
+	expEvents := []string{
+		"Warning HostnameRejected hostname 'echo2.example.com' is owned by namespace 'ns2'",
+		"Warning HostnameRejected hostname 'echo1.example.com' is owned by namespace 'ns1'",
+	}
+	for _, exp := range expEvents {
+		select {
+		case event := <-c.recorder.Events:
+			if event != exp {
+				t.Errorf("event differ, expected: %s, actual: %s", exp, event)
+			}
+		default:
+			t.Errorf("missing event: %s", exp)
+		}
+	}
+
+	c.logger.CompareLogging(`
+WARN skipping hostname 'echo2.example.com' of ingress 'ns1/echo2': hostname is owned by namespace 'ns2'
+WARN skipping hostname 'echo1.example.com' of ingress 'ns2/echo': hostname is owned by namespace 'ns1'`)
+}
+
+func TestSyncHostOwnershipEvents(t *testing.T) {
+	c := setup(t)
+	defer c.teardown()
+
+	c.hostOwnership = true
+	c.cache.HostOwners = map[string]string{
+		"echo.example.com": "ns1",
+	}
+	c.createSvc1("ns2/echo", "8080", "172.17.0.12")
+	c.createSecretTLS1("ns2/tls-echo")
+	ing := c.createIngTLS1("ns2/echo", "echo.example.com", "/", "echo:8080", "tls-echo")
+	ing.SetAnnotations(map[string]string{"ingress.kubernetes.io/cert-signer": "acme"})
+
+	// rules and acme tls declare the same hostname, on two distinct syncs
+	c.Sync(ing)
+	c.Sync(ing)
+
+	expEvents := []string{
+		"Warning HostnameRejected hostname 'echo.example.com' is owned by namespace 'ns1'",
+	}
+	var actualEvents []string
+	for len(c.recorder.Events) > 0 {
+		actualEvents = append(actualEvents, <-c.recorder.Events)
+	}
+	if !reflect.DeepEqual(actualEvents, expEvents) {
+		t.Errorf("events differ, expected: %v, actual: %v", expEvents, actualEvents)
+	}
+
+	c.logger.CompareLogging(`
+WARN skipping hostname 'echo.example.com' of ingress 'ns2/echo': hostname is owned by namespace 'ns1'
+WARN skipping hostname 'echo.example.com' of ingress 'ns2/echo': hostname is owned by namespace 'ns1'`)
+}
+
 func TestSyncNoEndpoint(t *testing.T) {
 	c := setup(t)
 	defer c.teardown()
@@ -317,6 +564,101 @@ func TestSyncDrainSupport(t *testing.T) {
 	c.logger.CompareLogging("WARN skipping endpoint 172.17.1.104 of service default/echo: port 'http' was not found")
 }
 
+func TestSyncNotReadyEndpoints(t *testing.T) {
+	testCases := []struct {
+		ann      map[string]string
+		config   map[string]string
+		expected string
+		logging  string
+	}{
+		// 0
+		{
+			expected: "172.17.1.101:8080=100",
+		},
+		// 1
+		{
+			ann:      map[string]string{"not-ready-endpoints": "ignore"},
+			expected: "172.17.1.101:8080=100",
+		},
+		// 2
+		{
+			config:   map[string]string{"drain-support": "true"},
+			expected: "172.17.1.101:8080=100,172.17.1.102:8080=0",
+		},
+		// 3
+		{
+			ann:      map[string]string{"not-ready-endpoints": "backup"},
+			expected: "172.17.1.101:8080=100,172.17.1.102:8080=100(backup)",
+		},
+		// 4
+		{
+			ann:      map[string]string{"not-ready-endpoints": "backup"},
+			config:   map[string]string{"drain-support": "true"},
+			expected: "172.17.1.101:8080=100,172.17.1.102:8080=100(backup)",
+		},
+		// 5
+		{
+			ann:      map[string]string{"not-ready-endpoints": "weight"},
+			expected: "172.17.1.101:8080=100,172.17.1.102:8080=0",
+		},
+		// 6
+		{
+			ann:      map[string]string{"not-ready-endpoints": "weight", "not-ready-weight": "10"},
+			expected: "172.17.1.101:8080=100,172.17.1.102:8080=10",
+		},
+		// 7
+		{
+			ann:      map[string]string{"not-ready-endpoints": "weight", "not-ready-weight": "-1"},
+			expected: "172.17.1.101:8080=100,172.17.1.102:8080=0",
+			logging:  `WARN invalid not ready weight '-1' on ingress 'default/echo', using '0' instead`,
+		},
+		// 8
+		{
+			ann:      map[string]string{"not-ready-endpoints": "weight", "not-ready-weight": "300"},
+			expected: "172.17.1.101:8080=100,172.17.1.102:8080=256",
+			logging:  `WARN invalid not ready weight '300' on ingress 'default/echo', using '256' instead`,
+		},
+		// 9
+		{
+			ann:      map[string]string{"not-ready-endpoints": "all"},
+			expected: "172.17.1.101:8080=100",
+			logging:  `WARN ignoring invalid not ready endpoints policy on ingress 'default/echo': all`,
+		},
+	}
+	for i, test := range testCases {
+		c := setup(t)
+		_, ep := c.createSvc1("default/echo", "http:8080:8080", "172.17.1.101,172.17.1.102")
+		ss := &ep.Subsets[0]
+		addr := ss.Addresses
+		ss.Addresses = []api.EndpointAddress{addr[0]}
+		ss.NotReadyAddresses = []api.EndpointAddress{addr[1]}
+		ann := map[string]string{}
+		for key, value := range test.ann {
+			ann["ingress.kubernetes.io/"+key] = value
+		}
+		config := test.config
+		if config == nil {
+			config = map[string]string{}
+		}
+		c.SyncDef(config, c.createIng1Ann("default/echo", "echo.example.com", "/", "echo:8080", ann))
+		backend := c.hconfig.Backends().FindBackend("default", "echo", "8080")
+		var endpoints []string
+		for _, ep := range backend.Endpoints {
+			endpoint := fmt.Sprintf("%s:%d=%d", ep.IP, ep.Port, ep.Weight)
+			if ep.Backup {
+				endpoint += "(backup)"
+			}
+			endpoints = append(endpoints, endpoint)
+		}
+		actual := strings.Join(endpoints, ",")
+		if actual != test.expected {
+			t.Errorf("endpoints differ on %d: expected '%s' but was '%s'", i, test.expected, actual)
+		}
+		c.logger.CompareLogging(test.logging)
+		c.teardown()
+	}
+}
+
 func TestSyncRootPathLast(t *testing.T) {
 	c := setup(t)
 	defer c.teardown()
@@ -419,6 +761,39 @@ func TestSyncRedeclarePath(t *testing.T) {
 WARN skipping redeclared path '/p1' of ingress 'default/echo1'`)
 }
 
+func TestSyncPathType(t *testing.T) {
+	c := setup(t)
+	defer c.teardown()
+
+	c.createSvc1("default/echo1", "8080", "172.17.0.11")
+	c.createSvc1("default/echo2", "8080", "172.17.0.12")
+	c.createSvc1("default/echo3", "8080", "172.17.0.13")
+	c.SyncDef(map[string]string{"path-type": "prefix"},
+		c.createIng1("default/echo1", "echo.example.com", "/", "echo1:8080"),
+		c.createIng1Ann("default/echo2", "echo.example.com", "/app", "echo2:8080", map[string]string{
+			"ingress.kubernetes.io/path-type": "exact",
+		}),
+		c.createIng1Ann("default/echo3", "echo.example.com", "/api", "echo3:8080", map[string]string{
+			"ingress.kubernetes.io/path-type": "invalid",
+		}),
+	)
+
+	c.compareConfigFront(`
+- hostname: echo.example.com
+  paths:
+  - path: /app
+    match: exact
+    backend: default_echo2_8080
+  - path: /api
+    backend: default_echo3_8080
+  - path: /
+    match: prefix
+    backend: default_echo1_8080`)
+
+	c.logger.CompareLogging(`
+WARN ignoring invalid path type 'invalid' of ingress 'default/echo3', using 'begin' instead`)
+}
+
 func TestSyncTLSDefault(t *testing.T) {
 	c := setup(t)
 	defer c.teardown()
@@ -1108,16 +1483,21 @@ type testConfig struct {
 	logger  *types_helper.LoggerMock
 	cache   *conv_helper.CacheMock
 	updater *updaterMock
+	// hostOwnership enables the hostname ownership policy on the converter
+	hostOwnership bool
+	rejectedHosts map[string]bool
+	recorder      *record.FakeRecorder
 }
 
 func setup(t *testing.T) *testConfig {
 	logger := types_helper.NewLoggerMock(t)
 	c := &testConfig{
-		t:       t,
-		decode:  scheme.Codecs.UniversalDeserializer().Decode,
-		hconfig: haproxy.CreateInstance(logger, haproxy.InstanceOptions{}).Config(),
-		cache:   conv_helper.NewCacheMock(),
-		logger:  logger,
+		t:        t,
+		decode:   scheme.Codecs.UniversalDeserializer().Decode,
+		hconfig:  haproxy.CreateInstance(logger, haproxy.InstanceOptions{}).Config(),
+		cache:    conv_helper.NewCacheMock(),
+		logger:   logger,
+		recorder: record.NewFakeRecorder(10),
 	}
 	c.createSvc1("system/default", "8080", "172.17.0.99")
 	return c
@@ -1140,7 +1520,8 @@ var defaultBackendConfig = `
 func (c *testConfig) SyncDef(config map[string]string, ing ...*extensions.Ingress) {
 	defaultConfig := func() map[string]string {
 		return map[string]string{
-			ingtypes.BackInitialWeight: "100",
+			ingtypes.BackInitialWeight:  "100",
+			ingtypes.BackNotReadyWeight: "0",
 		}
 	}
 	conv := NewIngressConverter(
@@ -1156,12 +1537,16 @@ func (c *testConfig) SyncDef(config map[string]string, ing ...*extensions.Ingres
 				NotAfter:   time.Now().AddDate(0, 0, 30),
 			},
 			AnnotationPrefix: "ingress.kubernetes.io",
+			HostOwnership:    c.hostOwnership,
+			RejectedHosts:    c.rejectedHosts,
+			EventRecorder:    c.recorder,
 		},
 		c.hconfig,
 		config,
 	).(*converter)
 	conv.updater = c.updater
 	conv.Sync(ing)
+	c.rejectedHosts = conv.options.RejectedHosts
 }
 
 func (c *testConfig) createSvc1Auto() (*api.Service, *api.Endpoints) {
@@ -1328,6 +1713,7 @@ func (u *updaterMock) UpdateBackendConfig(backend *hatypes.Backend, mapper *anno
 type (
 	pathMock struct {
 		Path      string
+		Match     string `yaml:",omitempty"`
 		BackendID string `yaml:"backend"`
 	}
 	timeoutMock struct {
@@ -1349,7 +1735,11 @@ func convertHost(hafronts ...*hatypes.Host) []hostMock {
 	for _, f := range hafronts {
 		paths := []pathMock{}
 		for _, p := range f.Paths {
-			paths = append(paths, pathMock{Path: p.Path, BackendID: p.Backend.ID})
+			var match string
+			if p.Match != hatypes.MatchBegin {
+				match = string(p.Match)
+			}
+			paths = append(paths, pathMock{Path: p.Path, Match: match, BackendID: p.Backend.ID})
 		}
 		hosts = append(hosts, hostMock{
 			Hostname:     f.Hostname,
@@ -1383,6 +1773,7 @@ type (
 	backendMock struct {
 		ID               string
 		Endpoints        []endpointMock `yaml:",omitempty"`
+		ExternalName     string         `yaml:",omitempty"`
 		BalanceAlgorithm string         `yaml:",omitempty"`
 		MaxConnServer    int            `yaml:",omitempty"`
 	}
@@ -1398,6 +1789,7 @@ func convertBackend(habackends ...*hatypes.Backend) []backendMock {
 		backends = append(backends, backendMock{
 			ID:               b.ID,
 			Endpoints:        endpoints,
+			ExternalName:     b.ExternalName,
 			BalanceAlgorithm: b.BalanceAlgorithm,
 			MaxConnServer:    b.Server.MaxConn,
 		})
@@ -50,6 +50,8 @@ var (
 
 // Backend Annotations
 const (
+	BackAccessLog              = "access-log"
+	BackAccessLogSample        = "access-log-sample"
 	BackAffinity               = "affinity"
 	BackAgentCheckAddr         = "agent-check-addr"
 	BackAgentCheckInterval     = "agent-check-interval"
@@ -95,17 +97,23 @@ const (
 	BackLimitWhitelist         = "limit-whitelist"
 	BackMaxconnServer          = "maxconn-server"
 	BackMaxQueueServer         = "maxqueue-server"
+	BackNotReadyEndpoints      = "not-ready-endpoints"
+	BackNotReadyWeight         = "not-ready-weight"
 	BackOAuth                  = "oauth"
 	BackOAuthHeaders           = "oauth-headers"
 	BackOAuthURIPrefix         = "oauth-uri-prefix"
+	BackPathType               = "path-type"
 	BackProxyBodySize          = "proxy-body-size"
 	BackProxyProtocol          = "proxy-protocol"
+	BackRequestIDForward       = "request-id-forward"
+	BackRequestIDResponse      = "request-id-response"
 	BackRewriteTarget          = "rewrite-target"
 	BackSlotsMinFree           = "slots-min-free"
 	BackSecureBackends         = "secure-backends"
 	BackSecureCrtSecret        = "secure-crt-secret"
 	BackSecureVerifyCASecret   = "secure-verify-ca-secret"
 	BackServiceUpstream        = "service-upstream"
+	BackServiceWeight          = "service-weight"
 	BackSessionCookieDynamic   = "session-cookie-dynamic"
 	BackSessionCookieName      = "session-cookie-name"
 	BackSessionCookieShared    = "session-cookie-shared"
@@ -125,6 +133,9 @@ const (
 	BackUseResolver            = "use-resolver"
 	BackWAF                    = "waf"
 	BackWAFMode                = "waf-mode"
+	BackWakeUpDeployment       = "wake-up-deployment"
+	BackWakeUpTimeout          = "wake-up-timeout"
+	BackWakeUpWebhook          = "wake-up-webhook"
 	BackWhitelistSourceRange   = "whitelist-source-range"
 )
 
@@ -64,7 +64,12 @@ const (
 	GlobalNbprocSSL                    = "nbproc-ssl"
 	GlobalNbthread                     = "nbthread"
 	GlobalNoTLSRedirectLocations       = "no-tls-redirect-locations"
+	GlobalPeersPort                    = "peers-port"
+	GlobalPeersSelector                = "peers-selector"
 	GlobalPrometheusPort               = "prometheus-port"
+	GlobalRequestID                    = "request-id"
+	GlobalRequestIDFormat              = "request-id-format"
+	GlobalRequestIDHeader              = "request-id-header"
 	GlobalSSLCiphers                   = "ssl-ciphers"
 	GlobalSSLCipherSuites              = "ssl-cipher-suites"
 	GlobalSSLDHDefaultMaxSize          = "ssl-dh-default-max-size"
@@ -17,6 +17,8 @@ limitations under the License.
 package types
 
 import (
+	"k8s.io/client-go/tools/record"
+
 	convtypes "github.com/jcmoraisjr/haproxy-ingress/pkg/converters/types"
 	"github.com/jcmoraisjr/haproxy-ingress/pkg/types"
 )
@@ -31,4 +33,10 @@ type ConverterOptions struct {
 	FakeCAFile       convtypes.CrtFile
 	AnnotationPrefix string
 	AcmeTrackTLSAnn  bool
+	AdminSocket      string
+	StateFile        string
+	HostOwnership    bool
+	RejectedHosts    map[string]bool
+	LogMetricsSocket string
+	EventRecorder    record.EventRecorder
 }
@@ -28,10 +28,14 @@ type Cache interface {
 	GetEndpoints(service *api.Service) (*api.Endpoints, error)
 	GetTerminatingPods(service *api.Service) ([]*api.Pod, error)
 	GetPod(podName string) (*api.Pod, error)
+	GetControllerPod() (*api.Pod, error)
+	GetControllerPods(selector string) ([]*api.Pod, error)
 	GetTLSSecretPath(defaultNamespace, secretName string) (CrtFile, error)
 	GetCASecretPath(defaultNamespace, secretName string) (ca, crl File, err error)
 	GetDHSecretPath(defaultNamespace, secretName string) (File, error)
 	GetSecretContent(defaultNamespace, secretName, keyName string) ([]byte, error)
+	GetHostOwners() (map[string]string, error)
+	SetHostOwners(owners map[string]string) error
 }
 
 // File ...
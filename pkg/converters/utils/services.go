@@ -71,6 +71,7 @@ type Endpoint struct {
 	IP        string
 	Port      int
 	TargetRef string
+	UID       string
 }
 
 // CreateEndpoints ...
@@ -135,10 +136,15 @@ func createEndpointsExternalName(svc *api.Service, svcPort *api.ServicePort) (en
 }
 
 func newEndpointAddr(addr *api.EndpointAddress, port int) *Endpoint {
+	var uid string
+	if addr.TargetRef != nil {
+		uid = string(addr.TargetRef.UID)
+	}
 	return &Endpoint{
 		IP:        addr.IP,
 		Port:      port,
 		TargetRef: targetRefToString(addr.TargetRef),
+		UID:       uid,
 	}
 }
 
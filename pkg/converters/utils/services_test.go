@@ -127,6 +127,26 @@ func TestCreateEndpoints(t *testing.T) {
 	}
 }
 
+func TestCreateEndpointsTargetRef(t *testing.T) {
+	c := setup(t)
+	defer c.teardown()
+
+	svc, ep := helper_test.CreateService("default/echo", "8080", "172.17.0.11,172.17.0.12")
+	ep.Subsets[0].Addresses[0].TargetRef.UID = "uid-1"
+	cache := &helper_test.CacheMock{
+		SvcList: []*api.Service{svc},
+		EpList:  map[string]*api.Endpoints{"default/echo": ep},
+	}
+	ready, _, _ := CreateEndpoints(cache, svc, FindServicePort(svc, "8080"))
+	expected := []*Endpoint{
+		{IP: "172.17.0.11", Port: 8080, TargetRef: "default/echo-xxxxx", UID: "uid-1"},
+		{IP: "172.17.0.12", Port: 8080, TargetRef: "default/echo-xxxxx"},
+	}
+	if !reflect.DeepEqual(ready, expected) {
+		t.Errorf("endpoints differ: expected=%+v actual=%+v", expected, ready)
+	}
+}
+
 type config struct {
 	t *testing.T
 }
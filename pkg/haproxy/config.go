@@ -242,7 +242,7 @@ func (c *config) WriteFrontendMaps() error {
 				hasSSLRedirect = backend.HasSSLRedirectHostpath(base)
 			}
 			// TODO use only root path if all uri has the same conf
-			fmaps.HTTPSRedirMap.AppendHostname(host.Hostname+path.Path, yesno[hasSSLRedirect])
+			fmaps.HTTPSRedirMap.AppendHostnameMatch(base, path.Match, yesno[hasSSLRedirect])
 			var aliasName, aliasRegex string
 			// TODO warn in logs about ignoring alias name due to hostname colision
 			if host.Alias.AliasName != "" && c.hosts.FindHost(host.Alias.AliasName) == nil {
@@ -252,19 +252,20 @@ func (c *config) WriteFrontendMaps() error {
 				aliasRegex = host.Alias.AliasRegex + path.Path
 			}
 			backendID := path.Backend.ID
+			match := path.Match
 			if host.HasTLSAuth() {
-				fmaps.SNIBackendsMap.AppendHostname(base, backendID)
-				fmaps.SNIBackendsMap.AppendAliasName(aliasName, backendID)
-				fmaps.SNIBackendsMap.AppendAliasRegex(aliasRegex, backendID)
+				fmaps.SNIBackendsMap.AppendHostnameMatch(base, match, backendID)
+				fmaps.SNIBackendsMap.AppendAliasName(aliasName, match, backendID)
+				fmaps.SNIBackendsMap.AppendAliasRegex(aliasRegex, match, backendID)
 			} else {
-				fmaps.HostBackendsMap.AppendHostname(base, backendID)
-				fmaps.HostBackendsMap.AppendAliasName(aliasName, backendID)
-				fmaps.HostBackendsMap.AppendAliasRegex(aliasRegex, backendID)
+				fmaps.HostBackendsMap.AppendHostnameMatch(base, match, backendID)
+				fmaps.HostBackendsMap.AppendAliasName(aliasName, match, backendID)
+				fmaps.HostBackendsMap.AppendAliasRegex(aliasRegex, match, backendID)
 			}
 			if !hasSSLRedirect || c.global.Bind.HasFrontingProxy() {
-				fmaps.HTTPFrontsMap.AppendHostname(base, backendID)
-				fmaps.HTTPFrontsMap.AppendAliasName(aliasName, backendID)
-				fmaps.HTTPFrontsMap.AppendAliasRegex(aliasRegex, backendID)
+				fmaps.HTTPFrontsMap.AppendHostnameMatch(base, match, backendID)
+				fmaps.HTTPFrontsMap.AppendAliasName(aliasName, match, backendID)
+				fmaps.HTTPFrontsMap.AppendAliasRegex(aliasRegex, match, backendID)
 			}
 			var ns string
 			if host.VarNamespace {
@@ -272,7 +273,7 @@ func (c *config) WriteFrontendMaps() error {
 			} else {
 				ns = "-"
 			}
-			fmaps.VarNamespaceMap.AppendHostname(base, ns)
+			fmaps.VarNamespaceMap.AppendHostnameMatch(base, match, ns)
 		}
 		if host.HasTLSAuth() {
 			fmaps.TLSInvalidCrtErrorList.AppendHostname(host.Hostname, "")
@@ -338,7 +339,7 @@ func (c *config) WriteBackendMaps() error {
 			mapsPrefix := c.mapsDir + "/_back_" + backend.ID
 			pathsMap := mapBuilder.AddMap(mapsPrefix + "_idpath.map")
 			for _, path := range backend.Paths {
-				pathsMap.AppendPath(path.Hostpath, path.ID)
+				pathsMap.AppendPath(path.Hostpath, path.Match, path.ID)
 			}
 			backend.PathsMap = pathsMap
 		}
@@ -351,6 +352,11 @@ func writeMaps(maps *hatypes.HostsMaps, template *template.Config) error {
 		if err := template.WriteOutput(hmap.Match, hmap.MatchFile); err != nil {
 			return err
 		}
+		if len(hmap.Exact) > 0 {
+			if err := template.WriteOutput(hmap.Exact, hmap.ExactFile); err != nil {
+				return err
+			}
+		}
 		if len(hmap.Regex) > 0 {
 			if err := template.WriteOutput(hmap.Regex, hmap.RegexFile); err != nil {
 				return err
@@ -0,0 +1,119 @@
+/*
+Copyright 2020 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package haproxy
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// lines that are expected to differ between controller replicas:
+	// empty server slots, whose amount depends on the dynamic updates
+	// the replica has applied
+	configHashSkipRegex = regexp.MustCompile(`^\s*server \S+ 127\.0\.0\.1:`)
+	// server names also depend on the history of the replica
+	configHashServerRegex = regexp.MustCompile(`^(\s*)(server|use-server) (\S+)(.*)$`)
+)
+
+// ConfigHash returns the hash of the last rendered configuration, an
+// empty string if HAProxy wasn't configured yet.
+func (i *instance) ConfigHash() string {
+	i.configHashMutex.Lock()
+	defer i.configHashMutex.Unlock()
+	return i.configHash
+}
+
+func (i *instance) updateConfigHash() {
+	hash, err := calcConfigHash(i.options.HAProxyConfigFile, i.mapsDir, ioutil.ReadFile)
+	if err != nil {
+		i.logger.Warn("error calculating configuration hash: %v", err)
+		return
+	}
+	i.configHashMutex.Lock()
+	i.configHash = hash
+	i.configHashMutex.Unlock()
+	i.metrics.SetConfigHash(hash)
+}
+
+// calcConfigHash calculates a sha256 hash of the configuration file and
+// all the map files it references. The configuration is normalized, so
+// replicas that converged to the same state have the same hash.
+func calcConfigHash(configFile, mapsDir string, readFile func(filename string) ([]byte, error)) (string, error) {
+	config, err := readFile(configFile)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	writeNormalizedConfig(h, config)
+	mapsRegex := regexp.MustCompile(regexp.QuoteMeta(mapsDir) + `/[^\s)]+`)
+	maps := make(map[string]bool)
+	for _, m := range mapsRegex.FindAll(config, -1) {
+		maps[string(m)] = true
+	}
+	mapFiles := make([]string, 0, len(maps))
+	for m := range maps {
+		mapFiles = append(mapFiles, m)
+	}
+	sort.Strings(mapFiles)
+	for _, mapFile := range mapFiles {
+		content, err := readFile(mapFile)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\n", mapFile)
+		h.Write(content)
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// writeNormalizedConfig writes the configuration into w, removing replica
+// specific lines and server names, and sorting every group of server lines.
+func writeNormalizedConfig(w io.Writer, config []byte) {
+	var servers []string
+	flushServers := func() {
+		sort.Strings(servers)
+		for _, server := range servers {
+			fmt.Fprintln(w, server)
+		}
+		servers = servers[:0]
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(config))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if configHashSkipRegex.MatchString(line) {
+			continue
+		}
+		if m := configHashServerRegex.FindStringSubmatch(line); m != nil {
+			// server name is also used as the cookie value
+			rest := strings.Replace(m[4], " cookie "+m[3], " cookie -", 1)
+			servers = append(servers, m[1]+m[2]+" -"+rest)
+			continue
+		}
+		flushServers()
+		fmt.Fprintln(w, line)
+	}
+	flushServers()
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2020 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package haproxy
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCalcConfigHash(t *testing.T) {
+	config1 := `
+global
+backend default_app_8080
+    http-request set-var(txn.pathID) base,lower,map_beg(/etc/haproxy/maps/_back_default_app_8080_idpath.map)
+    server srv001 172.17.0.11:8080 weight 100 cookie srv001
+    server srv002 172.17.0.12:8080 weight 100 cookie srv002
+    server srv003 127.0.0.1:1023 disabled weight 0 cookie srv003
+frontend _front_http
+    use_backend %[base,map_beg(/etc/haproxy/maps/_front001_host.map)]
+`
+	config2 := `
+global
+backend default_app_8080
+    http-request set-var(txn.pathID) base,lower,map_beg(/etc/haproxy/maps/_back_default_app_8080_idpath.map)
+    server srv001 172.17.0.12:8080 weight 100 cookie srv001
+    server srv002 127.0.0.1:1023 disabled weight 0 cookie srv002
+    server srv003 127.0.0.1:1023 disabled weight 0 cookie srv003
+    server srv004 172.17.0.11:8080 weight 100 cookie srv004
+frontend _front_http
+    use_backend %[base,map_beg(/etc/haproxy/maps/_front001_host.map)]
+`
+	config3 := `
+global
+backend default_app_8080
+    http-request set-var(txn.pathID) base,lower,map_beg(/etc/haproxy/maps/_back_default_app_8080_idpath.map)
+    server srv001 172.17.0.11:8080 weight 100 cookie srv001
+frontend _front_http
+    use_backend %[base,map_beg(/etc/haproxy/maps/_front001_host.map)]
+`
+	maps1 := map[string]string{
+		"/etc/haproxy/maps/_back_default_app_8080_idpath.map": "d1.local/ path01\n",
+		"/etc/haproxy/maps/_front001_host.map":                "d1.local/ default_app_8080\n",
+	}
+	maps2 := map[string]string{
+		"/etc/haproxy/maps/_back_default_app_8080_idpath.map": "d1.local/ path01\n",
+		"/etc/haproxy/maps/_front001_host.map":                "d1.local/ default_app_8080\nd2.local/ default_app_8080\n",
+	}
+	testCases := []struct {
+		config1, config2 string
+		maps1, maps2     map[string]string
+		equal            bool
+	}{
+		// 0
+		{
+			config1: config1,
+			config2: config1,
+			maps1:   maps1,
+			maps2:   maps1,
+			equal:   true,
+		},
+		// 1
+		{
+			config1: config1,
+			config2: config2,
+			maps1:   maps1,
+			maps2:   maps1,
+			equal:   true,
+		},
+		// 2
+		{
+			config1: config1,
+			config2: config3,
+			maps1:   maps1,
+			maps2:   maps1,
+			equal:   false,
+		},
+		// 3
+		{
+			config1: config1,
+			config2: config1,
+			maps1:   maps1,
+			maps2:   maps2,
+			equal:   false,
+		},
+	}
+	hash := func(config string, maps map[string]string) string {
+		h, err := calcConfigHash("/etc/haproxy/haproxy.cfg", "/etc/haproxy/maps", func(filename string) ([]byte, error) {
+			if filename == "/etc/haproxy/haproxy.cfg" {
+				return []byte(config), nil
+			}
+			if content, found := maps[filename]; found {
+				return []byte(content), nil
+			}
+			return nil, fmt.Errorf("file not found: %s", filename)
+		})
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		return h
+	}
+	for i, test := range testCases {
+		hash1 := hash(test.config1, test.maps1)
+		hash2 := hash(test.config2, test.maps2)
+		if (hash1 == hash2) != test.equal {
+			t.Errorf("hash comparison differs on %d -- expected equal: %v -- hash1: %s -- hash2: %s", i, test.equal, hash1, hash2)
+		}
+	}
+}
+
+func TestConfigHashReloadFailure(t *testing.T) {
+	c := setup(t)
+	defer c.teardown()
+	instance := c.instance.(*instance)
+	c.config.Backends().AcquireBackend("default", "app1", "8080").AcquireEndpoint("172.17.0.11", 8080, "")
+	c.Update()
+	hash := instance.ConfigHash()
+	if hash == "" {
+		t.Errorf("expected a configuration hash after a successful reload")
+	}
+
+	instance.options.ProcessManager = NewExecProcessManager(c.logger, ExecOptions{ReloadCmd: "false"})
+	c.config = c.newConfig()
+	instance.curConfig = c.config
+	c.config.Backends().AcquireBackend("default", "app2", "8080").AcquireEndpoint("172.17.0.12", 8080, "")
+	c.Update()
+	if actual := instance.ConfigHash(); actual != hash {
+		t.Errorf("hash of a configuration that failed to reload should not be used -- expected: %s -- actual: %s", hash, actual)
+	}
+	c.logger.CompareLogging(`
+INFO (test) reload was skipped
+INFO HAProxy successfully reloaded
+INFO-V(2) added backend 'default_app2_8080'
+ERROR error reloading server:
+exit status 1`)
+}
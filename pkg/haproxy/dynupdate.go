@@ -17,10 +17,14 @@ limitations under the License.
 package haproxy
 
 import (
+	"bufio"
 	"fmt"
+	"os"
 	"reflect"
+	"regexp"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	hatypes "github.com/jcmoraisjr/haproxy-ingress/pkg/haproxy/types"
@@ -36,6 +40,9 @@ type dynUpdater struct {
 	cmd     func(socket string, observer func(duration time.Duration), commands ...string) ([]string, error)
 	cmdCnt  int
 	metrics types.Metrics
+	// serverNames has the server names of the running haproxy,
+	// used if there isn't an old config
+	serverNames map[string]map[string]string
 }
 
 type backendPair struct {
@@ -56,20 +63,27 @@ func (i *instance) newDynUpdater() *dynUpdater {
 	if i.curConfig != nil {
 		cur = i.curConfig.(*config)
 	}
+	var serverNames map[string]map[string]string
+	if old == nil {
+		serverNames = readServerNames(i.options.HAProxyConfigFile)
+	}
 	return &dynUpdater{
-		logger:  i.logger,
-		old:     old,
-		cur:     cur,
-		socket:  i.curConfig.Global().AdminSocket,
-		cmd:     utils.HAProxyCommand,
-		metrics: i.metrics,
+		logger:      i.logger,
+		old:         old,
+		cur:         cur,
+		socket:      i.curConfig.Global().AdminSocket,
+		cmd:         utils.HAProxyCommand,
+		metrics:     i.metrics,
+		serverNames: serverNames,
 	}
 }
 
 func (d *dynUpdater) update() bool {
 	updated := d.checkConfigPair()
 	if !updated {
-		// Need to reload, time to adjust empty slots according to config
+		// Need to reload, time to reuse server names from the old config
+		// and adjust empty slots according to config
+		d.alignNames()
 		d.alignSlots()
 	}
 	return updated
@@ -173,6 +187,7 @@ func (d *dynUpdater) checkBackendPair(pair *backendPair) bool {
 	// map endpoints of old and new config together
 	endpoints := make(map[string]*epPair, len(oldBack.Endpoints))
 	targets := make([]string, 0, len(oldBack.Endpoints))
+	released := map[string]string{}
 	var empty []string
 	for _, endpoint := range oldBack.Endpoints {
 		if endpoint.Enabled {
@@ -203,29 +218,54 @@ func (d *dynUpdater) checkBackendPair(pair *backendPair) bool {
 	// try to dynamically remove/update/add endpoints
 	// targets used here only to have predictable results
 	// Endpoint.Label != "" means use-server of blue/green config, need reload
+	// Endpoint.Backup means a backup server, which cannot be changed dynamically
 	sort.Strings(targets)
 	for _, target := range targets {
 		pair := endpoints[target]
 		if pair.cur == nil {
-			if pair.old.Label != "" || (updated && !d.execDisableEndpoint(curBack.ID, pair.old)) {
+			if pair.old.Label != "" || pair.old.Backup || (updated && !d.execDisableEndpoint(curBack.ID, pair.old)) {
 				updated = false
 			}
 			empty = append(empty, pair.old.Name)
+			if pair.old.UID != "" {
+				released[pair.old.UID] = pair.old.Name
+			}
 		} else if updated && !d.checkEndpointPair(curBack.ID, pair) {
 			updated = false
 		}
 	}
-	for i := range added {
-		// reusing empty slots from oldBack
-		added[i].Name = empty[i]
-		if added[i].Label != "" || (updated && !d.execEnableEndpoint(curBack.ID, nil, added[i])) {
+	// a pod which changed its target reuses the name of its old server,
+	// assigned first so an earlier added endpoint cannot take it; the
+	// remaining ones reuse empty slots from oldBack
+	names := make([]string, len(added))
+	reused := make(map[string]bool, len(added))
+	for i, endpoint := range added {
+		if name, found := released[endpoint.UID]; found && endpoint.UID != "" {
+			delete(released, endpoint.UID)
+			names[i] = name
+			reused[name] = true
+		}
+	}
+	free := make([]string, 0, len(empty))
+	for _, name := range empty {
+		if !reused[name] {
+			free = append(free, name)
+		}
+	}
+	for i, endpoint := range added {
+		if names[i] == "" {
+			names[i] = free[0]
+			free = free[1:]
+		}
+		endpoint.Name = names[i]
+		if endpoint.Label != "" || endpoint.Backup || (updated && !d.execEnableEndpoint(curBack.ID, nil, endpoint)) {
 			updated = false
 		}
 	}
 
 	// copy remaining empty slots from oldBack to curBack, so it can be used in a future update
-	for i := len(added); i < len(empty); i++ {
-		curBack.AddEmptyEndpoint().Name = empty[i]
+	for _, name := range free {
+		curBack.AddEmptyEndpoint().Name = name
 	}
 	curBack.SortEndpoints()
 
@@ -236,12 +276,116 @@ func (d *dynUpdater) checkEndpointPair(backname string, pair *epPair) bool {
 	if reflect.DeepEqual(pair.old, pair.cur) {
 		return true
 	}
-	if pair.old.Label != "" || pair.cur.Label != "" {
+	if pair.old.Label != "" || pair.cur.Label != "" || pair.old.Backup != pair.cur.Backup {
 		return false
 	}
 	return d.execEnableEndpoint(backname, pair.old, pair.cur)
 }
 
+// alignNames gives every endpoint of a sequence named backend the same
+// server name it had in the old config, so a pod or a target keeps its
+// server identity, and its metrics, across reloads. Server names of the
+// running haproxy are used on the first update after the controller starts.
+func (d *dynUpdater) alignNames() {
+	if d.cur == nil || (d.old == nil && d.serverNames == nil) {
+		return
+	}
+	oldNames := make(map[string]map[string]string)
+	identity := epIdentity
+	if d.old != nil {
+		for _, back := range d.old.Backends().Items() {
+			names := make(map[string]string, len(back.Endpoints))
+			for _, ep := range back.Endpoints {
+				if !ep.IsEmpty() {
+					names[epIdentity(ep)] = ep.Name
+				}
+			}
+			oldNames[back.ID] = names
+		}
+	} else {
+		// the running config doesn't have pod UIDs
+		oldNames = d.serverNames
+		identity = func(ep *hatypes.Endpoint) string { return ep.Target }
+	}
+	for _, back := range d.cur.Backends().Items() {
+		names, found := oldNames[back.ID]
+		if !found || back.EpNaming != hatypes.EpSequence {
+			continue
+		}
+		used := make(map[string]bool, len(back.Endpoints))
+		var empty, unnamed []*hatypes.Endpoint
+		for _, ep := range back.Endpoints {
+			if ep.IsEmpty() {
+				empty = append(empty, ep)
+			} else if name, found := names[identity(ep)]; found && !used[name] {
+				ep.Name = name
+				used[name] = true
+			} else {
+				unnamed = append(unnamed, ep)
+			}
+		}
+		// empty slots keep their names if not claimed by a known endpoint
+		for _, ep := range empty {
+			if used[ep.Name] {
+				unnamed = append(unnamed, ep)
+			} else {
+				used[ep.Name] = true
+			}
+		}
+		next := 1
+		for _, ep := range unnamed {
+			for used[fmt.Sprintf("srv%03d", next)] {
+				next++
+			}
+			ep.Name = fmt.Sprintf("srv%03d", next)
+			used[ep.Name] = true
+		}
+		back.SortEndpoints()
+	}
+}
+
+// epIdentity is the key used to find the server name of an endpoint in the
+// old config: the pod UID if the endpoint has a target reference, the target
+// address otherwise. A pod recreated with the same name, eg from a
+// StatefulSet, has a new UID and is handled as a new endpoint.
+func epIdentity(ep *hatypes.Endpoint) string {
+	if ep.UID != "" {
+		return ep.UID
+	}
+	return ep.Target
+}
+
+var serverRegex = regexp.MustCompile(`^ +server (srv[0-9]+) ([^ ]+)`)
+
+// readServerNames reads the sequence server names of every backend of
+// a haproxy config file, indexed by backend ID and server target.
+func readServerNames(configFile string) map[string]map[string]string {
+	file, err := os.Open(configFile)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+	serverNames := make(map[string]map[string]string)
+	var names map[string]string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line != "" && line[0] != ' ' && line[0] != '#' {
+			// a new section, only servers of backends are used
+			names = nil
+			if fields := strings.Fields(line); len(fields) == 2 && fields[0] == "backend" {
+				names = make(map[string]string)
+				serverNames[fields[1]] = names
+			}
+		} else if match := serverRegex.FindStringSubmatch(line); match != nil && names != nil {
+			if target := match[2]; target != "127.0.0.1:1023" {
+				names[target] = match[1]
+			}
+		}
+	}
+	return serverNames
+}
+
 func (d *dynUpdater) alignSlots() {
 	if d.cur == nil {
 		return
@@ -256,6 +400,19 @@ func (d *dynUpdater) alignSlots() {
 		if blockSize < 1 {
 			blockSize = 1
 		}
+		// names might not follow the sequence after alignNames()
+		used := make(map[string]bool, len(back.Endpoints))
+		for _, ep := range back.Endpoints {
+			used[ep.Name] = true
+		}
+		next := len(back.Endpoints)
+		addEmptyEndpoint := func() {
+			next++
+			for used[fmt.Sprintf("srv%03d", next)] {
+				next++
+			}
+			back.AddEmptyEndpoint().Name = fmt.Sprintf("srv%03d", next)
+		}
 		var newFreeSlots int
 		if minFreeSlots == 0 && len(back.Endpoints) == 0 {
 			newFreeSlots = blockSize
@@ -267,7 +424,7 @@ func (d *dynUpdater) alignSlots() {
 				}
 			}
 			for i := totalFreeSlots; i < minFreeSlots; i++ {
-				back.AddEmptyEndpoint()
+				addEmptyEndpoint()
 			}
 			// * []endpoints == group of blocks
 			// * block == group of slots
@@ -276,7 +433,7 @@ func (d *dynUpdater) alignSlots() {
 			newFreeSlots = blockSize - (((len(back.Endpoints) + blockSize - 1) % blockSize) + 1)
 		}
 		for i := 0; i < newFreeSlots; i++ {
-			back.AddEmptyEndpoint()
+			addEmptyEndpoint()
 		}
 	}
 }
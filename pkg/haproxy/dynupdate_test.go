@@ -18,6 +18,7 @@ package haproxy
 
 import (
 	"fmt"
+	"io/ioutil"
 	"reflect"
 	"strings"
 	"testing"
@@ -32,6 +33,7 @@ func TestDynUpdate(t *testing.T) {
 		curConfig *config
 		doconfig1 func(c *testConfig)
 		doconfig2 func(c *testConfig)
+		running   string
 		expected  []string
 		dynamic   bool
 		cmd       string
@@ -84,8 +86,8 @@ func TestDynUpdate(t *testing.T) {
 				b.AcquireEndpoint("172.17.0.3", 8080, "")
 			},
 			expected: []string{
-				"srv001:172.17.0.2:8080:1",
-				"srv002:172.17.0.3:8080:1",
+				"srv001:172.17.0.3:8080:1",
+				"srv002:172.17.0.2:8080:1",
 				"srv003:127.0.0.1:1023:1",
 				"srv004:127.0.0.1:1023:1",
 				"srv005:127.0.0.1:1023:1",
@@ -490,7 +492,7 @@ set server default_app_8080/srv002 weight 1`,
 				b.AcquireEndpoint("172.17.0.3", 8080, "")
 			},
 			expected: []string{
-				"srv001:172.17.0.3:8080:1",
+				"srv002:172.17.0.3:8080:1",
 			},
 			dynamic: false,
 			logging: `INFO-V(2) backend 'default_app_8080' changed and its dynamic-scaling is 'false'`,
@@ -514,10 +516,195 @@ set server default_app_8080/srv002 weight 1`,
 			},
 			dynamic: true,
 		},
+		// 23
+		{
+			doconfig1: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.Dynamic.DynUpdate = true
+				b.AcquireEndpoint("172.17.0.2", 8080, "default/app-1").UID = "uid-1"
+				b.AcquireEndpoint("172.17.0.3", 8080, "default/app-2").UID = "uid-2"
+				b.AddEmptyEndpoint()
+			},
+			doconfig2: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.Dynamic.DynUpdate = true
+				b.AcquireEndpoint("172.17.0.2", 8080, "default/app-1").UID = "uid-1"
+				b.AcquireEndpoint("172.17.0.4", 8080, "default/app-2").UID = "uid-2"
+			},
+			expected: []string{
+				"srv001:172.17.0.2:8080:1",
+				"srv002:172.17.0.4:8080:1",
+				"srv003:127.0.0.1:1023:1",
+			},
+			dynamic: true,
+			cmd: `
+set server default_app_8080/srv002 state maint
+set server default_app_8080/srv002 addr 127.0.0.1 port 1023
+set server default_app_8080/srv002 weight 0
+set server default_app_8080/srv002 addr 172.17.0.4 port 8080
+set server default_app_8080/srv002 state ready
+set server default_app_8080/srv002 weight 1
+`,
+			logging: `
+INFO-V(2) disabled endpoint '172.17.0.3:8080' on backend/server 'default_app_8080/srv002'
+INFO-V(2) added endpoint '172.17.0.4:8080' weight '1' state 'ready' on backend/server 'default_app_8080/srv002'`,
+		},
+		// 24
+		{
+			doconfig1: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "default/app-1")
+				b.AcquireEndpoint("172.17.0.3", 8080, "default/app-2")
+				b.AcquireEndpoint("172.17.0.4", 8080, "default/app-3")
+			},
+			doconfig2: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.3", 8080, "default/app-2")
+				b.AcquireEndpoint("172.17.0.4", 8080, "default/app-3")
+				b.AcquireEndpoint("172.17.0.5", 8080, "default/app-4")
+			},
+			expected: []string{
+				"srv001:172.17.0.5:8080:1",
+				"srv002:172.17.0.3:8080:1",
+				"srv003:172.17.0.4:8080:1",
+			},
+			dynamic: false,
+			logging: `INFO-V(2) backend 'default_app_8080' changed and its dynamic-scaling is 'false'`,
+		},
+		// 25
+		{
+			doconfig1: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				b.AcquireEndpoint("172.17.0.3", 8080, "").Backup = true
+			},
+			doconfig2: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.Dynamic.DynUpdate = true
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				b.AcquireEndpoint("172.17.0.3", 8080, "")
+			},
+			expected: []string{
+				"srv001:172.17.0.2:8080:1",
+				"srv002:172.17.0.3:8080:1",
+			},
+			dynamic: false,
+		},
+		// 26
+		{
+			doconfig1: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				b.AddEmptyEndpoint()
+			},
+			doconfig2: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.Dynamic.DynUpdate = true
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				b.AcquireEndpoint("172.17.0.3", 8080, "").Backup = true
+			},
+			expected: []string{
+				"srv001:172.17.0.2:8080:1",
+				"srv002:172.17.0.3:8080:1",
+			},
+			dynamic: false,
+		},
+		// 27
+		{
+			doconfig1: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "default/app-0").UID = "uid-a"
+				b.AcquireEndpoint("172.17.0.3", 8080, "default/app-1").UID = "uid-b"
+				b.AcquireEndpoint("172.17.0.4", 8080, "default/app-2").UID = "uid-c"
+			},
+			doconfig2: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.3", 8080, "default/app-1").UID = "uid-b"
+				b.AcquireEndpoint("172.17.0.4", 8080, "default/app-2").UID = "uid-c"
+				b.AcquireEndpoint("172.17.0.6", 8080, "default/app-3").UID = "uid-e"
+				b.AcquireEndpoint("172.17.0.5", 8080, "default/app-0").UID = "uid-d"
+			},
+			expected: []string{
+				"srv001:172.17.0.6:8080:1",
+				"srv002:172.17.0.3:8080:1",
+				"srv003:172.17.0.4:8080:1",
+				"srv004:172.17.0.5:8080:1",
+			},
+			dynamic: false,
+			logging: `INFO-V(2) added endpoints on backend 'default_app_8080'`,
+		},
+		// 28
+		{
+			running: `
+backend default_app_8080
+    mode http
+    server srv001 172.17.0.2:8080 weight 1
+    server srv002 172.17.0.3:8080 weight 1
+    server srv003 127.0.0.1:1023 disabled weight 1
+listen _tcp_default_app_5432
+    server srv009 172.17.0.4:8080 weight 1
+`,
+			doconfig2: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.3", 8080, "default/app-1").UID = "uid-b"
+				b.AcquireEndpoint("172.17.0.4", 8080, "default/app-2").UID = "uid-c"
+				b.AcquireEndpoint("172.17.0.5", 8080, "default/app-3").UID = "uid-d"
+			},
+			expected: []string{
+				"srv001:172.17.0.4:8080:1",
+				"srv002:172.17.0.3:8080:1",
+				"srv003:172.17.0.5:8080:1",
+			},
+			dynamic: false,
+		},
+		// 29
+		{
+			doconfig1: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.Dynamic.DynUpdate = true
+				b.AcquireEndpoint("172.17.0.2", 8080, "default/app-1").UID = "uid-1"
+				b.AcquireEndpoint("172.17.0.3", 8080, "default/app-2").UID = "uid-2"
+			},
+			doconfig2: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.Dynamic.DynUpdate = true
+				b.AcquireEndpoint("172.17.0.4", 8080, "default/app-3").UID = "uid-3"
+				b.AcquireEndpoint("172.17.0.5", 8080, "default/app-1").UID = "uid-1"
+			},
+			expected: []string{
+				"srv001:172.17.0.5:8080:1",
+				"srv002:172.17.0.4:8080:1",
+			},
+			dynamic: true,
+			cmd: `
+set server default_app_8080/srv001 state maint
+set server default_app_8080/srv001 addr 127.0.0.1 port 1023
+set server default_app_8080/srv001 weight 0
+set server default_app_8080/srv002 state maint
+set server default_app_8080/srv002 addr 127.0.0.1 port 1023
+set server default_app_8080/srv002 weight 0
+set server default_app_8080/srv002 addr 172.17.0.4 port 8080
+set server default_app_8080/srv002 state ready
+set server default_app_8080/srv002 weight 1
+set server default_app_8080/srv001 addr 172.17.0.5 port 8080
+set server default_app_8080/srv001 state ready
+set server default_app_8080/srv001 weight 1
+`,
+			logging: `
+INFO-V(2) disabled endpoint '172.17.0.2:8080' on backend/server 'default_app_8080/srv001'
+INFO-V(2) disabled endpoint '172.17.0.3:8080' on backend/server 'default_app_8080/srv002'
+INFO-V(2) added endpoint '172.17.0.4:8080' weight '1' state 'ready' on backend/server 'default_app_8080/srv002'
+INFO-V(2) added endpoint '172.17.0.5:8080' weight '1' state 'ready' on backend/server 'default_app_8080/srv001'`,
+		},
 	}
 	for i, test := range testCases {
 		c := setup(t)
 		instance := c.instance.(*instance)
+		if test.running != "" {
+			if err := ioutil.WriteFile(instance.options.HAProxyConfigFile, []byte(test.running), 0644); err != nil {
+				t.Errorf("error writing running config on %d: %v", i, err)
+			}
+		}
 		if test.doconfig1 != nil {
 			test.doconfig1(c)
 			test.oldConfig = c.config.(*config)
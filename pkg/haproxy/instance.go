@@ -18,12 +18,13 @@ package haproxy
 
 import (
 	"fmt"
-	"os/exec"
+	"path/filepath"
 	"reflect"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/jcmoraisjr/haproxy-ingress/pkg/acme"
 	"github.com/jcmoraisjr/haproxy-ingress/pkg/haproxy/template"
@@ -37,13 +38,18 @@ import (
 type InstanceOptions struct {
 	AcmeSigner        acme.Signer
 	AcmeQueue         utils.Queue
+	AdminSocket       string
 	LeaderElector     types.LeaderElector
 	MaxOldConfigFiles int
 	HAProxyCmd        string
 	HAProxyConfigFile string
+	MapsDir           string
 	Metrics           types.Metrics
+	ProcessManager    ProcessManager
 	ReloadCmd         string
 	ReloadStrategy    string
+	StateFile         string
+	TemplatesDir      string
 	ValidateConfig    bool
 }
 
@@ -53,17 +59,40 @@ type Instance interface {
 	ParseTemplates() error
 	Config() Config
 	CalcIdleMetric()
+	CalcTCPServicesMetric()
+	ConfigHash() string
+	LogRequest(line string)
+	WakeUpBackends() []*hatypes.Backend
 	Update(timer *utils.Timer)
+	WriteConfig() error
 }
 
 // CreateInstance ...
 func CreateInstance(logger types.Logger, options InstanceOptions) Instance {
+	if options.HAProxyConfigFile == "" {
+		options.HAProxyConfigFile = "/etc/haproxy/haproxy.cfg"
+	}
+	if options.MapsDir == "" {
+		options.MapsDir = "/etc/haproxy/maps"
+	}
+	if options.TemplatesDir == "" {
+		options.TemplatesDir = "/etc/haproxy"
+	}
+	if options.ProcessManager == nil {
+		options.ProcessManager = NewExecProcessManager(logger, ExecOptions{
+			HAProxyCmd:     options.HAProxyCmd,
+			ReloadCmd:      options.ReloadCmd,
+			ReloadStrategy: options.ReloadStrategy,
+			AdminSocket:    options.AdminSocket,
+			StateFile:      options.StateFile,
+		})
+	}
 	return &instance{
 		logger:       logger,
 		options:      &options,
 		templates:    template.CreateConfig(),
 		mapsTemplate: template.CreateConfig(),
-		mapsDir:      "/etc/haproxy/maps",
+		mapsDir:      options.MapsDir,
 		metrics:      options.Metrics,
 	}
 }
@@ -77,6 +106,19 @@ type instance struct {
 	oldConfig    Config
 	curConfig    Config
 	metrics      types.Metrics
+	//
+	// oldConfigMutex guards writes of oldConfig and its reads made
+	// outside of the sync goroutine, eg from metrics collectors
+	oldConfigMutex sync.Mutex
+	//
+	tcpStats      map[string]tcpServiceStats
+	tcpStatsMutex sync.Mutex
+	//
+	configHash      string
+	configHashMutex sync.Mutex
+	//
+	logRoutes      map[string]*logBackend
+	logRoutesMutex sync.Mutex
 }
 
 func (i *instance) AcmeCheck(source string) (int, error) {
@@ -145,10 +187,11 @@ func (i *instance) acmeRemoveCert(storage string, domains map[string]struct{}) {
 func (i *instance) ParseTemplates() error {
 	i.templates.ClearTemplates()
 	i.mapsTemplate.ClearTemplates()
+	templatesDir := i.options.TemplatesDir
 	if err := i.templates.NewTemplate(
 		"spoe-modsecurity.tmpl",
-		"/etc/haproxy/modsecurity/spoe-modsecurity.tmpl",
-		"/etc/haproxy/spoe-modsecurity.conf",
+		templatesDir+"/modsecurity/spoe-modsecurity.tmpl",
+		filepath.Dir(i.options.HAProxyConfigFile)+"/spoe-modsecurity.conf",
 		0,
 		1024,
 	); err != nil {
@@ -156,8 +199,8 @@ func (i *instance) ParseTemplates() error {
 	}
 	if err := i.templates.NewTemplate(
 		"haproxy.tmpl",
-		"/etc/haproxy/template/haproxy.tmpl",
-		"/etc/haproxy/haproxy.cfg",
+		templatesDir+"/template/haproxy.tmpl",
+		i.options.HAProxyConfigFile,
 		i.options.MaxOldConfigFiles,
 		16384,
 	); err != nil {
@@ -165,7 +208,7 @@ func (i *instance) ParseTemplates() error {
 	}
 	err := i.mapsTemplate.NewTemplate(
 		"map.tmpl",
-		"/etc/haproxy/maptemplate/map.tmpl",
+		templatesDir+"/maptemplate/map.tmpl",
 		"",
 		0,
 		2048,
@@ -187,10 +230,11 @@ func (i *instance) Config() Config {
 var idleRegex = regexp.MustCompile(`Idle_pct: ([0-9]+)`)
 
 func (i *instance) CalcIdleMetric() {
-	if i.oldConfig == nil {
+	config := i.runningConfig()
+	if config == nil {
 		return
 	}
-	msg, err := hautils.HAProxyCommand(i.oldConfig.Global().AdminSocket, i.metrics.HAProxyShowInfoResponseTime, "show info")
+	msg, err := hautils.HAProxyCommand(config.Global().AdminSocket, i.metrics.HAProxyShowInfoResponseTime, "show info")
 	if err != nil {
 		i.logger.Error("error reading admin socket: %v", err)
 		return
@@ -212,6 +256,27 @@ func (i *instance) Update(timer *utils.Timer) {
 	i.haproxyUpdate(timer)
 }
 
+// WriteConfig writes the map files and the configuration files of the
+// current configuration, without dynamically updating or reloading haproxy.
+func (i *instance) WriteConfig() error {
+	if i.curConfig == nil {
+		return fmt.Errorf("new configuration is empty")
+	}
+	defer i.rotateConfig()
+	i.curConfig.SyncConfig()
+	if err := i.curConfig.WriteFrontendMaps(); err != nil {
+		return err
+	}
+	if err := i.curConfig.WriteBackendMaps(); err != nil {
+		return err
+	}
+	// server names and empty slots as if haproxy would be reloaded
+	updater := i.newDynUpdater()
+	updater.alignNames()
+	updater.alignSlots()
+	return i.templates.Write(i.curConfig)
+}
+
 func (i *instance) acmeUpdate() {
 	if i.oldConfig == nil || i.curConfig == nil || i.options.AcmeQueue == nil {
 		return
@@ -304,6 +369,7 @@ func (i *instance) haproxyUpdate(timer *utils.Timer) {
 				timer.Tick("validate_cfg")
 				i.metrics.UpdateSuccessful(err == nil)
 			}
+			i.updateRunningState()
 			i.logger.Info("HAProxy updated without needing to reload. Commands sent: %d", updater.cmdCnt)
 			i.metrics.IncUpdateDynamic()
 		} else {
@@ -320,6 +386,8 @@ func (i *instance) haproxyUpdate(timer *utils.Timer) {
 		return
 	}
 	timer.Tick("reload_haproxy")
+	i.updateRunningState()
+	i.resetTCPServicesMetric()
 	i.metrics.UpdateSuccessful(true)
 	i.logger.Info("HAProxy successfully reloaded")
 }
@@ -354,37 +422,34 @@ func (i *instance) updateCertExpiring() {
 	}
 }
 
+// updateRunningState updates the state derived from the configuration files,
+// it should be called only after haproxy successfully applied them.
+func (i *instance) updateRunningState() {
+	i.updateConfigHash()
+	i.updateLogRoutes()
+}
+
 func (i *instance) check() error {
-	if i.options.HAProxyCmd == "" {
-		i.logger.Info("(test) check was skipped")
-		return nil
-	}
-	out, err := exec.Command(i.options.HAProxyCmd, "-c", "-f", i.options.HAProxyConfigFile).CombinedOutput()
-	outstr := string(out)
-	if err != nil {
-		return fmt.Errorf(outstr)
-	}
-	return nil
+	return i.options.ProcessManager.Check(i.options.HAProxyConfigFile)
 }
 
 func (i *instance) reload() error {
-	if i.options.ReloadCmd == "" {
-		i.logger.Info("(test) reload was skipped")
-		return nil
-	}
-	out, err := exec.Command(i.options.ReloadCmd, i.options.ReloadStrategy, i.options.HAProxyConfigFile).CombinedOutput()
-	outstr := string(out)
-	if len(outstr) > 0 {
-		i.logger.Warn("output from haproxy:\n%v", outstr)
-	}
-	if err != nil {
-		return err
-	}
-	return nil
+	return i.options.ProcessManager.Reload(i.options.HAProxyConfigFile)
 }
 
 func (i *instance) rotateConfig() {
 	// TODO releaseConfig (old support files, ...)
+	i.oldConfigMutex.Lock()
 	i.oldConfig = i.curConfig
+	i.oldConfigMutex.Unlock()
 	i.curConfig = nil
 }
+
+// runningConfig returns the configuration applied in the last update.
+// Use it instead of reading oldConfig outside of the sync goroutine, a
+// Config isn't changed after it is rotated.
+func (i *instance) runningConfig() Config {
+	i.oldConfigMutex.Lock()
+	defer i.oldConfigMutex.Unlock()
+	return i.oldConfig
+}
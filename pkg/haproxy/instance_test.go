@@ -175,6 +175,27 @@ func TestBackends(t *testing.T) {
     http-request set-header X-Original-Forwarded-For %[hdr(x-forwarded-for)] if { hdr(x-forwarded-for) -m found }
     http-request del-header x-forwarded-for
     option forwardfor`,
+		},
+		{
+			doconfig: func(g *hatypes.Global, h *hatypes.Host, b *hatypes.Backend) {
+				b.AccessLog.Disabled = true
+			},
+			expected: `
+    http-request set-log-level silent`,
+		},
+		{
+			doconfig: func(g *hatypes.Global, h *hatypes.Host, b *hatypes.Backend) {
+				b.AccessLog.OnlyErrors = true
+			},
+			expected: `
+    http-response set-log-level silent if { status lt 400 }`,
+		},
+		{
+			doconfig: func(g *hatypes.Global, h *hatypes.Host, b *hatypes.Backend) {
+				b.AccessLog.Sample = 100
+			},
+			expected: `
+    http-response set-log-level silent if { status lt 400 } { rand(100) gt 0 }`,
 		},
 		{
 			doconfig: func(g *hatypes.Global, h *hatypes.Host, b *hatypes.Backend) {
@@ -238,6 +259,14 @@ func TestBackends(t *testing.T) {
     http-request replace-uri ^/path1(.*)$       /sub1\1     if { var(txn.pathID) path01 }
     http-request replace-uri ^/path2(.*)$       /sub2\1     if { var(txn.pathID) path02 }
     http-request replace-uri ^/path3(.*)$       /sub2\1     if { var(txn.pathID) path03 }`,
+		},
+		{
+			doconfig: func(g *hatypes.Global, h *hatypes.Host, b *hatypes.Backend) {
+				b.WakeUp.Deployment = "app"
+				b.WakeUp.Timeout = 30000
+			},
+			expected: `
+    http-request lua.wait-backend 30000`,
 		},
 		{
 			doconfig: func(g *hatypes.Global, h *hatypes.Host, b *hatypes.Backend) {
@@ -499,6 +528,17 @@ func TestBackends(t *testing.T) {
 			},
 			srvsuffix: "send-proxy-v2",
 		},
+		{
+			doconfig: func(g *hatypes.Global, h *hatypes.Host, b *hatypes.Backend) {
+				e1, e2 := *endpointS31, *endpointS32
+				b.Endpoints = []*hatypes.Endpoint{&e1, &e2}
+				b.Endpoints[1].Backup = true
+			},
+			skipSrv: true,
+			expected: `
+    server s31 172.17.0.131:8080 weight 100
+    server s32 172.17.0.132:8080 weight 100 backup`,
+		},
 		{
 			doconfig: func(g *hatypes.Global, h *hatypes.Host, b *hatypes.Backend) {
 				b.BlueGreen.CookieName = "ServerName"
@@ -1247,6 +1287,110 @@ backend _error404
 	}
 }
 
+func TestInstanceTCPBackendLog(t *testing.T) {
+	c := setup(t)
+	defer c.teardown()
+
+	var b *hatypes.TCPBackend
+
+	b = c.config.AcquireTCPBackend("pq", 5432)
+	b.AddEndpoint("172.17.0.2", 5432)
+	b.SSL.Filename = "/var/haproxy/ssl/pq.pem"
+	b.LogFormat = "detailed"
+
+	b = c.config.AcquireTCPBackend("pq", 5433)
+	b.AddEndpoint("172.17.0.3", 5432)
+	b.LogFormat = "detailed"
+
+	b = c.config.AcquireTCPBackend("pq", 5434)
+	b.AddEndpoint("172.17.0.4", 5432)
+	b.LogFormat = "tcplog"
+
+	b = c.config.AcquireTCPBackend("pq", 5435)
+	b.AddEndpoint("172.17.0.5", 5432)
+	b.LogFormat = "none"
+
+	b = c.config.AcquireTCPBackend("pq", 5436)
+	b.AddEndpoint("172.17.0.6", 5432)
+
+	syslog := &c.config.Global().Syslog
+	syslog.Endpoint = "127.0.0.1:1514"
+	syslog.Format = "rfc3164"
+	syslog.Length = 2048
+	syslog.Tag = "ingress"
+	syslog.TCPLogFormat = "default"
+
+	c.Update()
+	c.checkConfig(`
+global
+    daemon
+    unix-bind user haproxy group haproxy mode 0600
+    stats socket /var/run/haproxy.sock level admin expose-fd listeners mode 600
+    maxconn 2000
+    hard-stop-after 15m
+    log 127.0.0.1:1514 len 2048 format rfc3164 local0
+    log-tag ingress
+    lua-load /usr/local/etc/haproxy/lua/auth-request.lua
+    lua-load /usr/local/etc/haproxy/lua/services.lua
+    ssl-dh-param-file /var/haproxy/tls/dhparam.pem
+    ssl-default-bind-ciphers ECDHE-RSA-AES128-GCM-SHA256:ECDHE-ECDSA-AES128-GCM-SHA256
+    ssl-default-bind-ciphersuites TLS_AES_128_GCM_SHA256
+    ssl-default-bind-options no-sslv3
+    ssl-default-server-ciphers ECDHE-RSA-AES128-GCM-SHA256:ECDHE-ECDSA-AES128-GCM-SHA256
+    ssl-default-server-ciphersuites TLS_AES_128_GCM_SHA256
+    ssl-default-server-options no-sslv3
+<<defaults>>
+listen _tcp_pq_5432
+    bind :5432 ssl crt /var/haproxy/ssl/pq.pem
+    mode tcp
+    log-format %ci:%cp\ [%t]\ %ft\ %b/%s\ %Th/%Tw/%Tc/%Tt\ %U/%B\ %ts\ %ac/%fc/%bc/%sc/%rc\ %sq/%bq\ %[ssl_fc_sni]
+    server srv001 172.17.0.2:5432
+listen _tcp_pq_5433
+    bind :5433
+    mode tcp
+    log-format %ci:%cp\ [%t]\ %ft\ %b/%s\ %Th/%Tw/%Tc/%Tt\ %U/%B\ %ts\ %ac/%fc/%bc/%sc/%rc\ %sq/%bq
+    server srv001 172.17.0.3:5432
+listen _tcp_pq_5434
+    bind :5434
+    mode tcp
+    option tcplog
+    server srv001 172.17.0.4:5432
+listen _tcp_pq_5435
+    bind :5435
+    mode tcp
+    no log
+    server srv001 172.17.0.5:5432
+listen _tcp_pq_5436
+    bind :5436
+    mode tcp
+    option tcplog
+    server srv001 172.17.0.6:5432
+backend _error404
+    mode http
+    http-request use-service lua.send-404
+frontend _front_http
+    mode http
+    bind :80
+    option httplog
+    http-request set-var(req.base) base,lower,regsub(:[0-9]+/,/)
+    http-request redirect scheme https if { var(req.base),map_beg(/etc/haproxy/maps/_global_https_redir.map) yes }
+    <<http-headers>>
+    http-request set-var(req.backend) var(req.base),map_beg(/etc/haproxy/maps/_global_http_front.map)
+    use_backend %[var(req.backend)] if { var(req.backend) -m found }
+    default_backend _error404
+frontend _front001
+    mode http
+    bind :443 ssl alpn h2,http/1.1 crt-list /etc/haproxy/maps/_front001_bind_crt.list ca-ignore-err all crt-ignore-err all
+    option httplog
+    http-request set-var(req.hostbackend) base,lower,regsub(:[0-9]+/,/),map_beg(/etc/haproxy/maps/_front001_host.map)
+    <<https-headers>>
+    use_backend %[var(req.hostbackend)] if { var(req.hostbackend) -m found }
+    default_backend _error404
+<<support>>
+`)
+	c.logger.CompareLogging(defaultLogging)
+}
+
 func TestInstanceDefaultHost(t *testing.T) {
 	c := setup(t)
 	defer c.teardown()
@@ -1708,6 +1852,103 @@ d.local/ d_app0_8080
 	c.logger.CompareLogging(defaultLogging)
 }
 
+func TestInstancePathType(t *testing.T) {
+	c := setup(t)
+	defer c.teardown()
+
+	var h *hatypes.Host
+	var b *hatypes.Backend
+
+	b = c.config.Backends().AcquireBackend("d", "app0", "8080")
+	b.Endpoints = []*hatypes.Endpoint{endpointS1}
+	h = c.config.Hosts().AcquireHost("d.local")
+	h.AddPath(b, "/")
+
+	b = c.config.Backends().AcquireBackend("d", "app1", "8080")
+	b.Endpoints = []*hatypes.Endpoint{endpointS1}
+	h.AddPathMatch(b, "/app", hatypes.MatchPrefix)
+
+	b = c.config.Backends().AcquireBackend("d", "app2", "8080")
+	b.Endpoints = []*hatypes.Endpoint{endpointS21}
+	h.AddPathMatch(b, "/app/sub", hatypes.MatchExact)
+	h = c.config.Hosts().AcquireHost("*.d.local")
+	h.AddPathMatch(b, "/app/sub", hatypes.MatchExact)
+	h.AddPathMatch(b, "/app/", hatypes.MatchPrefix)
+
+	b = c.config.Backends().AcquireBackend("d", "app3", "8080")
+	b.Endpoints = []*hatypes.Endpoint{endpointS31}
+	h = c.config.Hosts().AcquireHost("*")
+	h.AddPathMatch(b, "/login", hatypes.MatchExact)
+	h.AddPathMatch(b, "/api", hatypes.MatchPrefix)
+
+	c.Update()
+	c.checkConfig(`
+<<global>>
+<<defaults>>
+backend d_app0_8080
+    mode http
+    server s1 172.17.0.11:8080 weight 100
+backend d_app1_8080
+    mode http
+    server s1 172.17.0.11:8080 weight 100
+backend d_app2_8080
+    mode http
+    server s21 172.17.0.121:8080 weight 100
+backend d_app3_8080
+    mode http
+    server s31 172.17.0.131:8080 weight 100
+<<backends-default>>
+frontend _front_http
+    mode http
+    bind :80
+    http-request set-var(req.base) base,lower,regsub(:[0-9]+/,/)
+    http-request set-var(req.redir) var(req.base),map(/etc/haproxy/maps/_global_https_redir_exact.map)
+    http-request set-var(req.redir) var(req.base),map_beg(/etc/haproxy/maps/_global_https_redir.map) if !{ var(req.redir) -m found }
+    http-request redirect scheme https if { var(req.redir) yes }
+    http-request redirect scheme https if !{ var(req.redir) -m found } { var(req.base),map_reg(/etc/haproxy/maps/_global_https_redir_regex.map) yes }
+    <<http-headers>>
+    http-request set-var(req.backend) var(req.base),map(/etc/haproxy/maps/_global_http_front_exact.map)
+    http-request set-var(req.backend) var(req.base),map_beg(/etc/haproxy/maps/_global_http_front.map) if !{ var(req.backend) -m found }
+    http-request set-var(req.backend) var(req.base),map_reg(/etc/haproxy/maps/_global_http_front_regex.map) if !{ var(req.backend) -m found }
+    use_backend %[var(req.backend)] if { var(req.backend) -m found }
+    use_backend d_app3_8080 if { path /login }
+    use_backend d_app3_8080 if { path_dir /api }
+    default_backend _error404
+frontend _front001
+    mode http
+    bind :443 ssl alpn h2,http/1.1 crt-list /etc/haproxy/maps/_front001_bind_crt.list ca-ignore-err all crt-ignore-err all
+    http-request set-var(req.base) base,lower,regsub(:[0-9]+/,/)
+    http-request set-var(req.hostbackend) var(req.base),map(/etc/haproxy/maps/_front001_host_exact.map)
+    http-request set-var(req.hostbackend) var(req.base),map_beg(/etc/haproxy/maps/_front001_host.map) if !{ var(req.hostbackend) -m found }
+    http-request set-var(req.hostbackend) var(req.base),map_reg(/etc/haproxy/maps/_front001_host_regex.map) if !{ var(req.hostbackend) -m found }
+    <<https-headers>>
+    use_backend %[var(req.hostbackend)] if { var(req.hostbackend) -m found }
+    use_backend d_app3_8080 if { path /login }
+    use_backend d_app3_8080 if { path_dir /api }
+    default_backend _error404
+<<support>>
+`)
+
+	c.checkMap("_global_http_front_exact.map", `
+d.local/app/sub d_app2_8080
+d.local/app d_app1_8080
+`)
+	c.checkMap("_global_http_front.map", `
+d.local/app/ d_app1_8080
+d.local/ d_app0_8080
+`)
+	c.checkMap("_global_http_front_regex.map", `
+^[^.]+\.d\.local/app/sub$ d_app2_8080
+^[^.]+\.d\.local/app(/|$) d_app2_8080
+`)
+	c.checkMap("_front001_host_exact.map", `
+d.local/app/sub d_app2_8080
+d.local/app d_app1_8080
+`)
+
+	c.logger.CompareLogging(defaultLogging)
+}
+
 func TestInstanceCustomFrontend(t *testing.T) {
 	c := setup(t)
 	defer c.teardown()
@@ -2137,6 +2378,282 @@ frontend _front001
 	c.logger.CompareLogging(defaultLogging)
 }
 
+func TestInstanceSyslogMetrics(t *testing.T) {
+	c := setup(t)
+	defer c.teardown()
+
+	var h *hatypes.Host
+	var b *hatypes.Backend
+
+	b = c.config.Backends().AcquireBackend("d1", "app", "8080")
+	b.Endpoints = []*hatypes.Endpoint{endpointS1}
+	b.AccessLog.OnlyErrors = true
+	h = c.config.Hosts().AcquireHost("d1.local")
+	h.AddPath(b, "/")
+
+	syslog := &c.config.Global().Syslog
+	syslog.Length = 1024
+	syslog.MetricsSocket = "/var/run/haproxy-log.sock"
+
+	c.Update()
+	c.checkConfig(`
+global
+    daemon
+    unix-bind user haproxy group haproxy mode 0600
+    stats socket /var/run/haproxy.sock level admin expose-fd listeners mode 600
+    maxconn 2000
+    hard-stop-after 15m
+    log unix@/var/run/haproxy-log.sock len 1024 format raw local0
+    lua-load /usr/local/etc/haproxy/lua/auth-request.lua
+    lua-load /usr/local/etc/haproxy/lua/services.lua
+    ssl-dh-param-file /var/haproxy/tls/dhparam.pem
+    ssl-default-bind-ciphers ECDHE-RSA-AES128-GCM-SHA256:ECDHE-ECDSA-AES128-GCM-SHA256
+    ssl-default-bind-ciphersuites TLS_AES_128_GCM_SHA256
+    ssl-default-bind-options no-sslv3
+    ssl-default-server-ciphers ECDHE-RSA-AES128-GCM-SHA256:ECDHE-ECDSA-AES128-GCM-SHA256
+    ssl-default-server-ciphersuites TLS_AES_128_GCM_SHA256
+    ssl-default-server-options no-sslv3
+<<defaults>>
+backend d1_app_8080
+    mode http
+    http-response set-log-level debug if { status lt 400 }
+    server s1 172.17.0.11:8080 weight 100
+<<backends-default>>
+frontend _front_http
+    mode http
+    bind :80
+    option httplog
+    http-request capture req.hdr(host) len 255
+    http-request set-var(req.base) base,lower,regsub(:[0-9]+/,/)
+    http-request redirect scheme https if { var(req.base),map_beg(/etc/haproxy/maps/_global_https_redir.map) yes }
+    <<http-headers>>
+    http-request set-var(req.backend) var(req.base),map_beg(/etc/haproxy/maps/_global_http_front.map)
+    use_backend %[var(req.backend)] if { var(req.backend) -m found }
+    default_backend _error404
+frontend _front001
+    mode http
+    bind :443 ssl alpn h2,http/1.1 crt-list /etc/haproxy/maps/_front001_bind_crt.list ca-ignore-err all crt-ignore-err all
+    option httplog
+    http-request capture req.hdr(host) len 255
+    http-request set-var(req.hostbackend) base,lower,regsub(:[0-9]+/,/),map_beg(/etc/haproxy/maps/_front001_host.map)
+    <<https-headers>>
+    use_backend %[var(req.hostbackend)] if { var(req.hostbackend) -m found }
+    default_backend _error404
+<<support>>
+`)
+	c.logger.CompareLogging(defaultLogging)
+}
+
+func TestInstanceSyslogMetricsTCP(t *testing.T) {
+	c := setup(t)
+	defer c.teardown()
+
+	var h *hatypes.Host
+	var b *hatypes.Backend
+	var tb *hatypes.TCPBackend
+
+	b = c.config.Backends().AcquireBackend("d1", "app", "8080")
+	b.Endpoints = []*hatypes.Endpoint{endpointS1}
+	h = c.config.Hosts().AcquireHost("d1.local")
+	h.AddPath(b, "/")
+	h.SetSSLPassthrough(true)
+
+	tb = c.config.AcquireTCPBackend("pq", 5432)
+	tb.AddEndpoint("172.17.0.2", 5432)
+	tb.LogFormat = "tcplog"
+
+	tb = c.config.AcquireTCPBackend("pq", 5433)
+	tb.AddEndpoint("172.17.0.3", 5432)
+	tb.LogFormat = "none"
+
+	syslog := &c.config.Global().Syslog
+	syslog.Endpoint = "127.0.0.1:1514"
+	syslog.Format = "rfc3164"
+	syslog.Length = 2048
+	syslog.Tag = "ingress"
+	syslog.HTTPSLogFormat = "default"
+	syslog.MetricsSocket = "/var/run/haproxy-log.sock"
+
+	c.Update()
+	c.checkConfig(`
+global
+    daemon
+    unix-bind user haproxy group haproxy mode 0600
+    stats socket /var/run/haproxy.sock level admin expose-fd listeners mode 600
+    maxconn 2000
+    hard-stop-after 15m
+    log 127.0.0.1:1514 len 2048 format rfc3164 local0 info
+    log-tag ingress
+    log unix@/var/run/haproxy-log.sock len 2048 format raw local0
+    lua-load /usr/local/etc/haproxy/lua/auth-request.lua
+    lua-load /usr/local/etc/haproxy/lua/services.lua
+    ssl-dh-param-file /var/haproxy/tls/dhparam.pem
+    ssl-default-bind-ciphers ECDHE-RSA-AES128-GCM-SHA256:ECDHE-ECDSA-AES128-GCM-SHA256
+    ssl-default-bind-ciphersuites TLS_AES_128_GCM_SHA256
+    ssl-default-bind-options no-sslv3
+    ssl-default-server-ciphers ECDHE-RSA-AES128-GCM-SHA256:ECDHE-ECDSA-AES128-GCM-SHA256
+    ssl-default-server-ciphersuites TLS_AES_128_GCM_SHA256
+    ssl-default-server-options no-sslv3
+<<defaults>>
+listen _tcp_pq_5432
+    bind :5432
+    mode tcp
+    no log
+    log 127.0.0.1:1514 len 2048 format rfc3164 local0
+    option tcplog
+    server srv001 172.17.0.2:5432
+listen _tcp_pq_5433
+    bind :5433
+    mode tcp
+    no log
+    server srv001 172.17.0.3:5432
+backend d1_app_8080
+    mode http
+    server s1 172.17.0.11:8080 weight 100
+<<backends-default>>
+listen _front__tls
+    mode tcp
+    bind :443
+    no log
+    log 127.0.0.1:1514 len 2048 format rfc3164 local0
+    option tcplog
+    tcp-request inspect-delay 5s
+    tcp-request content set-var(req.sslpassback) req.ssl_sni,lower,map(/etc/haproxy/maps/_global_sslpassthrough.map)
+    tcp-request content accept if { req.ssl_hello_type 1 }
+    use_backend %[var(req.sslpassback)] if { var(req.sslpassback) -m found }
+    # default backend
+    server _default_server_front001_socket unix@/var/run/_front001_socket.sock send-proxy-v2
+frontend _front_http
+    mode http
+    bind :80
+    option httplog
+    http-request capture req.hdr(host) len 255
+    http-request set-var(req.base) base,lower,regsub(:[0-9]+/,/)
+    http-request redirect scheme https if { var(req.base),map_beg(/etc/haproxy/maps/_global_https_redir.map) yes }
+    <<http-headers>>
+    http-request set-var(req.backend) var(req.base),map_beg(/etc/haproxy/maps/_global_http_front.map)
+    use_backend %[var(req.backend)] if { var(req.backend) -m found }
+    default_backend _error404
+frontend _front001
+    mode http
+    bind unix@/var/run/_front001_socket.sock accept-proxy ssl alpn h2,http/1.1 crt-list /etc/haproxy/maps/_front001_bind_crt.list ca-ignore-err all crt-ignore-err all
+    option httplog
+    http-request capture req.hdr(host) len 255
+    http-request set-var(req.hostbackend) base,lower,regsub(:[0-9]+/,/),map_beg(/etc/haproxy/maps/_front001_host.map)
+    <<https-headers>>
+    use_backend %[var(req.hostbackend)] if { var(req.hostbackend) -m found }
+    default_backend _error404
+<<support>>
+`)
+	c.logger.CompareLogging(defaultLogging)
+}
+
+func TestInstancePeers(t *testing.T) {
+	c := setup(t)
+	defer c.teardown()
+
+	var h *hatypes.Host
+	var b *hatypes.Backend
+
+	b = c.config.Backends().AcquireBackend("d1", "app", "8080")
+	b.Endpoints = []*hatypes.Endpoint{endpointS1}
+	b.Limit.RPS = 20
+	h = c.config.Hosts().AcquireHost("d1.local")
+	h.AddPath(b, "/")
+
+	peers := &c.config.Global().Peers
+	peers.LocalPeer = "ingress-1"
+	peers.Servers = []*hatypes.PeerServer{
+		{Name: "ingress-1", IP: "10.0.0.11", Port: 10000},
+		{Name: "ingress-2", IP: "10.0.0.12", Port: 10000},
+	}
+
+	c.Update()
+	c.checkConfig(`
+<<global>>
+<<defaults>>
+peers ingress
+    peer ingress-1 10.0.0.11:10000
+    peer ingress-2 10.0.0.12:10000
+backend d1_app_8080
+    mode http
+    stick-table type ip size 200k expire 5m store conn_cur,conn_rate(1s) peers ingress
+    http-request track-sc1 src
+    http-request deny deny_status 429 if { sc1_conn_rate gt 20 }
+    server s1 172.17.0.11:8080 weight 100
+<<backends-default>>
+<<frontends-default>>
+<<support>>
+`)
+	c.logger.CompareLogging(defaultLogging)
+}
+
+func TestInstanceRequestID(t *testing.T) {
+	c := setup(t)
+	defer c.teardown()
+
+	var h *hatypes.Host
+	var b *hatypes.Backend
+
+	b = c.config.Backends().AcquireBackend("d1", "app", "8080")
+	b.Endpoints = []*hatypes.Endpoint{endpointS1}
+	b.RequestID.Forward = true
+	b.RequestID.Response = true
+	h = c.config.Hosts().AcquireHost("d1.local")
+	h.AddPath(b, "/")
+
+	b = c.config.Backends().AcquireBackend("d2", "app", "8080")
+	b.Endpoints = []*hatypes.Endpoint{endpointS21}
+	h = c.config.Hosts().AcquireHost("d2.local")
+	h.AddPath(b, "/")
+
+	requestID := &c.config.Global().RequestID
+	requestID.Enabled = true
+	requestID.Format = `%{+X}o\ %ci:%cp_%fi:%fp_%Ts_%rt:%pid`
+	requestID.Header = "X-Request-ID"
+
+	c.Update()
+	c.checkConfig(`
+<<global>>
+<<defaults>>
+backend d1_app_8080
+    mode http
+    http-request set-header X-Request-ID %[var(txn.request_id)]
+    http-response set-header X-Request-ID %[var(txn.request_id)]
+    server s1 172.17.0.11:8080 weight 100
+backend d2_app_8080
+    mode http
+    server s21 172.17.0.121:8080 weight 100
+<<backends-default>>
+frontend _front_http
+    mode http
+    bind :80
+    unique-id-format %{+X}o\ %ci:%cp_%fi:%fp_%Ts_%rt:%pid
+    http-request set-var(txn.request_id) req.hdr(X-Request-ID)
+    http-request set-var(txn.request_id) unique-id if !{ var(txn.request_id) -m found }
+    http-request capture var(txn.request_id) len 64
+    http-request set-var(req.base) base,lower,regsub(:[0-9]+/,/)
+    http-request redirect scheme https if { var(req.base),map_beg(/etc/haproxy/maps/_global_https_redir.map) yes }
+    <<http-headers>>
+    http-request set-var(req.backend) var(req.base),map_beg(/etc/haproxy/maps/_global_http_front.map)
+    use_backend %[var(req.backend)] if { var(req.backend) -m found }
+    default_backend _error404
+frontend _front001
+    mode http
+    bind :443 ssl alpn h2,http/1.1 crt-list /etc/haproxy/maps/_front001_bind_crt.list ca-ignore-err all crt-ignore-err all
+    unique-id-format %{+X}o\ %ci:%cp_%fi:%fp_%Ts_%rt:%pid
+    http-request set-var(txn.request_id) req.hdr(X-Request-ID)
+    http-request set-var(txn.request_id) unique-id if !{ var(txn.request_id) -m found }
+    http-request capture var(txn.request_id) len 64
+    http-request set-var(req.hostbackend) base,lower,regsub(:[0-9]+/,/),map_beg(/etc/haproxy/maps/_front001_host.map)
+    <<https-headers>>
+    use_backend %[var(req.hostbackend)] if { var(req.hostbackend) -m found }
+    default_backend _error404
+<<support>>
+`)
+	c.logger.CompareLogging(defaultLogging)
+}
+
 func TestDNS(t *testing.T) {
 	c := setup(t)
 	defer c.teardown()
@@ -2168,6 +2685,14 @@ func TestDNS(t *testing.T) {
 				HoldValid:           "1s",
 				TimeoutRetry:        "2s",
 			},
+			{
+				Name:                "ext",
+				AcceptedPayloadSize: 8192,
+				HoldObsolete:        "0s",
+				HoldValid:           "1s",
+				ParseResolvConf:     true,
+				TimeoutRetry:        "2s",
+			},
 		},
 	}
 
@@ -2183,6 +2708,12 @@ func TestDNS(t *testing.T) {
 	h = c.config.Hosts().AcquireHost("d2.local")
 	h.AddPath(b, "/")
 
+	b = c.config.Backends().AcquireBackend("d3", "app", "8080")
+	b.ExternalName = "app.domain.local"
+	b.Resolver = "ext"
+	h = c.config.Hosts().AcquireHost("d3.local")
+	h.AddPath(b, "/")
+
 	c.Update()
 	c.checkConfig(`
 <<global>>
@@ -2195,12 +2726,21 @@ resolvers k8s
     hold obsolete         0s
     hold valid            1s
     timeout retry         2s
+resolvers ext
+    parse-resolv-conf
+    accepted_payload_size 8192
+    hold obsolete         0s
+    hold valid            1s
+    timeout retry         2s
 backend d1_app_8080
     mode http
     server-template srv 2 app.d1.svc.cluster.local:8080 resolvers k8s resolve-prefer ipv4 init-addr none weight 1
 backend d2_app_http
     mode http
     server-template srv 2 _http._tcp.app.d2.svc.cluster.local resolvers k8s resolve-prefer ipv4 init-addr none weight 1
+backend d3_app_8080
+    mode http
+    server-template srv 1 app.domain.local:8080 resolvers ext resolve-prefer ipv4 init-addr none weight 1
 <<backends-default>>
 <<frontends-default>>
 <<support>>
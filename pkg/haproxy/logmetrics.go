@@ -0,0 +1,203 @@
+/*
+Copyright 2020 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package haproxy
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	hatypes "github.com/jcmoraisjr/haproxy-ingress/pkg/haproxy/types"
+)
+
+// requestLogRegex reads the backend, the total active time, the status code
+// and the request line of a HTTP log. It matches the default HTTP log format,
+// `option httplog`, and also custom log formats with the same sequence of
+// fields: `%b/%s %TR/%Tw/%Tc/%Tr/%Ta %ST` and `"%r"`.
+var requestLogRegex = regexp.MustCompile(` ([^ /]+)/[^ ]+ -?[0-9]+/-?[0-9]+/-?[0-9]+/-?[0-9]+/\+?(-?[0-9]+) (-?[0-9]+)(?: .*"[A-Z]+ ([^ "]+))?`)
+
+// requestHostRegex reads the hostname of a HTTP log: the first captured
+// request header, `%hr` on the log format, which is the Host header captured
+// on the frontends if log metrics is enabled.
+var requestHostRegex = regexp.MustCompile(` \{([^|} ]*)[|}]`)
+
+type requestLog struct {
+	backend  string
+	hostname string
+	path     string
+	status   int
+	duration time.Duration
+}
+
+type logRoute struct {
+	hostname string
+	path     string
+	match    hatypes.MatchType
+	ingress  string
+}
+
+type logBackend struct {
+	namespace string
+	service   string
+	routes    []*logRoute
+}
+
+// LogRequest parses a HTTP log line sent by HAProxy and updates the
+// request metrics of the ingress and the backend that handled it.
+// Lines that cannot be parsed or whose backend isn't known are ignored.
+func (i *instance) LogRequest(line string) {
+	req := parseRequestLog(line)
+	if req == nil {
+		return
+	}
+	i.logRoutesMutex.Lock()
+	backend := i.logRoutes[req.backend]
+	i.logRoutesMutex.Unlock()
+	if backend == nil {
+		return
+	}
+	ingress := backend.findIngress(req.hostname, req.path)
+	i.metrics.ObserveBackendRequest(backend.namespace, ingress, backend.service, req.backend, req.status, req.duration)
+}
+
+func (i *instance) updateLogRoutes() {
+	var routes map[string]*logBackend
+	if i.curConfig.Global().Syslog.MetricsSocket != "" {
+		routes = buildLogRoutes(i.curConfig.Hosts(), i.curConfig.Backends())
+	}
+	i.logRoutesMutex.Lock()
+	i.logRoutes = routes
+	i.logRoutesMutex.Unlock()
+}
+
+func buildLogRoutes(hosts *hatypes.Hosts, backends *hatypes.Backends) map[string]*logBackend {
+	routes := make(map[string]*logBackend, len(backends.Items()))
+	for _, backend := range backends.Items() {
+		routes[backend.ID] = &logBackend{
+			namespace: backend.Namespace,
+			service:   backend.Name,
+		}
+	}
+	addRoutes := func(host *hatypes.Host) {
+		for _, path := range host.Paths {
+			if backend := routes[path.Backend.ID]; backend != nil {
+				backend.routes = append(backend.routes, &logRoute{
+					hostname: host.Hostname,
+					path:     strings.ToLower(path.Path),
+					match:    path.Match,
+					ingress:  path.Ingress,
+				})
+			}
+		}
+	}
+	for _, host := range hosts.Items() {
+		addRoutes(host)
+	}
+	if defaultHost := hosts.DefaultHost(); defaultHost != nil {
+		addRoutes(defaultHost)
+	}
+	for _, backend := range routes {
+		// same precedence used on the frontend: exact match
+		// first, the longest path first on the remaining ones
+		sort.SliceStable(backend.routes, func(i, j int) bool {
+			r1 := backend.routes[i]
+			r2 := backend.routes[j]
+			if (r1.match == hatypes.MatchExact) != (r2.match == hatypes.MatchExact) {
+				return r1.match == hatypes.MatchExact
+			}
+			return r1.path > r2.path
+		})
+	}
+	return routes
+}
+
+// findIngress returns the ingress which declared the hostname and the path
+// used to route the request. Hostnames are tried in the same precedence used
+// on the frontend: the hostname itself, its wildcard and the default host.
+// The first ingress whose path matches, despite the hostname, is used if the
+// log doesn't have the hostname, eg a custom log format without `%hr`, or if
+// the request was routed via a server alias.
+func (b *logBackend) findIngress(hostname, path string) string {
+	path = strings.ToLower(path)
+	if hostname != "" {
+		hostnames := []string{hostname}
+		if pos := strings.Index(hostname, "."); pos >= 0 {
+			hostnames = append(hostnames, "*"+hostname[pos:])
+		}
+		hostnames = append(hostnames, "*")
+		for _, h := range hostnames {
+			for _, route := range b.routes {
+				if route.hostname == h && route.matchPath(path) {
+					return route.ingress
+				}
+			}
+		}
+	}
+	for _, route := range b.routes {
+		if route.matchPath(path) {
+			return route.ingress
+		}
+	}
+	return ""
+}
+
+func (r *logRoute) matchPath(path string) bool {
+	switch r.match {
+	case hatypes.MatchExact:
+		return path == r.path
+	case hatypes.MatchPrefix:
+		prefix := strings.TrimSuffix(r.path, "/")
+		return path == prefix || strings.HasPrefix(path, prefix+"/")
+	default:
+		return strings.HasPrefix(path, r.path)
+	}
+}
+
+func parseRequestLog(line string) *requestLog {
+	match := requestLogRegex.FindStringSubmatch(line)
+	if match == nil {
+		return nil
+	}
+	duration, err := strconv.Atoi(match[2])
+	if err != nil {
+		return nil
+	}
+	status, err := strconv.Atoi(match[3])
+	if err != nil {
+		return nil
+	}
+	path := match[4]
+	if pos := strings.Index(path, "?"); pos >= 0 {
+		path = path[:pos]
+	}
+	var hostname string
+	if host := requestHostRegex.FindStringSubmatch(line); host != nil {
+		hostname = strings.ToLower(host[1])
+		if pos := strings.Index(hostname, ":"); pos >= 0 {
+			hostname = hostname[:pos]
+		}
+	}
+	return &requestLog{
+		backend:  match[1],
+		hostname: hostname,
+		path:     path,
+		status:   status,
+		duration: time.Duration(duration) * time.Millisecond,
+	}
+}
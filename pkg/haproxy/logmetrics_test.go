@@ -0,0 +1,246 @@
+/*
+Copyright 2020 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package haproxy
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	hatypes "github.com/jcmoraisjr/haproxy-ingress/pkg/haproxy/types"
+)
+
+func TestParseRequestLog(t *testing.T) {
+	testCases := []struct {
+		line     string
+		expected *requestLog
+	}{
+		// 0
+		{
+			line: `10.0.0.1:40000 [16/Oct/2020:10:00:00.123] _front_http default_app_8080/srv001 0/0/1/10/12 200 512 - - ---- 1/1/0/0/0 0/0 "GET /app/sub?q=1 HTTP/1.1"`,
+			expected: &requestLog{
+				backend:  "default_app_8080",
+				path:     "/app/sub",
+				status:   200,
+				duration: 12 * time.Millisecond,
+			},
+		},
+		// 1
+		{
+			line: `10.0.0.1:40000 [16/Oct/2020:10:00:00.123] _front001 default_app_8080/srv001 0/0/1/10/12 200 512 - - ---- 1/1/0/0/0 0/0 {D1.local:443|abc-123} "GET /app HTTP/1.1"`,
+			expected: &requestLog{
+				backend:  "default_app_8080",
+				hostname: "d1.local",
+				path:     "/app",
+				status:   200,
+				duration: 12 * time.Millisecond,
+			},
+		},
+		// 2
+		{
+			line: `<134>Oct 16 10:00:00 ingress[100]: 10.0.0.1:40000 [16/Oct/2020:10:00:00.123] _front001~ default_app_8080/srv002 0/0/-1/-1/3003 503 212 - - sC-- 1/1/0/0/3 0/0 "POST / HTTP/1.1"`,
+			expected: &requestLog{
+				backend:  "default_app_8080",
+				path:     "/",
+				status:   503,
+				duration: 3003 * time.Millisecond,
+			},
+		},
+		// 3
+		{
+			line: `10.0.0.1:40000 [16/Oct/2020:10:00:00.123] _front_http _error404/<NOSRV> 0/-1/-1/-1/+0 404 135 - - ---- 1/1/0/0/0 0/0 "GET /missing HTTP/1.1"`,
+			expected: &requestLog{
+				backend:  "_error404",
+				path:     "/missing",
+				status:   404,
+				duration: 0,
+			},
+		},
+		// 4
+		{
+			line: `10.0.0.1:40000 [16/Oct/2020:10:00:00.123] _front_http default_app_8080/srv001 1/0/0/5/6 302`,
+			expected: &requestLog{
+				backend:  "default_app_8080",
+				status:   302,
+				duration: 6 * time.Millisecond,
+			},
+		},
+		// 5
+		{
+			line: `10.0.0.1:40000 [16/Oct/2020:10:00:00.123] _tcp_default_pg_5432 default_pg_5432/srv001 0/1/3000 2048 -- 1/1/0/0/0 0/0`,
+		},
+		// 6
+		{
+			line: `Proxy _front_http started.`,
+		},
+	}
+	for i, test := range testCases {
+		actual := parseRequestLog(test.line)
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("request log differs on %d -- expected: %+v -- actual: %+v", i, test.expected, actual)
+		}
+	}
+}
+
+func TestLogRoutes(t *testing.T) {
+	type path struct {
+		hostname string
+		path     string
+		match    hatypes.MatchType
+		ingress  string
+	}
+	testCases := []struct {
+		paths    []path
+		hostname string
+		request  string
+		expected string
+	}{
+		// 0
+		{
+			paths: []path{
+				{"d1.local", "/", hatypes.MatchBegin, "default/ing1"},
+			},
+			request:  "/app",
+			expected: "default/ing1",
+		},
+		// 1
+		{
+			paths: []path{
+				{"d1.local", "/", hatypes.MatchBegin, "default/ing1"},
+				{"d1.local", "/app", hatypes.MatchBegin, "default/ing2"},
+			},
+			request:  "/App/sub",
+			expected: "default/ing2",
+		},
+		// 2
+		{
+			paths: []path{
+				{"d1.local", "/app", hatypes.MatchBegin, "default/ing1"},
+				{"d1.local", "/app/sub", hatypes.MatchExact, "default/ing2"},
+			},
+			request:  "/app/sub",
+			expected: "default/ing2",
+		},
+		// 3
+		{
+			paths: []path{
+				{"d1.local", "/", hatypes.MatchBegin, "default/ing1"},
+				{"d1.local", "/app/", hatypes.MatchPrefix, "default/ing2"},
+			},
+			request:  "/application",
+			expected: "default/ing1",
+		},
+		// 4
+		{
+			paths: []path{
+				{"d1.local", "/", hatypes.MatchBegin, "default/ing1"},
+				{"d1.local", "/app/", hatypes.MatchPrefix, "default/ing2"},
+			},
+			request:  "/app",
+			expected: "default/ing2",
+		},
+		// 5
+		{
+			paths: []path{
+				{"d1.local", "/app", hatypes.MatchExact, "default/ing1"},
+				{"*", "/", hatypes.MatchBegin, "default/ing2"},
+			},
+			request:  "/app/sub",
+			expected: "default/ing2",
+		},
+		// 6
+		{
+			paths: []path{
+				{"d1.local", "/app", hatypes.MatchExact, "default/ing1"},
+			},
+			request:  "/",
+			expected: "",
+		},
+		// 7
+		{
+			paths: []path{
+				{"d1.local", "/", hatypes.MatchBegin, "default/ing1"},
+				{"d2.local", "/", hatypes.MatchBegin, "default/ing2"},
+			},
+			hostname: "d2.local",
+			request:  "/app",
+			expected: "default/ing2",
+		},
+		// 8
+		{
+			paths: []path{
+				{"d1.local", "/", hatypes.MatchBegin, "default/ing1"},
+				{"*.d1.local", "/", hatypes.MatchBegin, "default/ing2"},
+				{"*", "/", hatypes.MatchBegin, "default/ing3"},
+			},
+			hostname: "sub.d1.local",
+			request:  "/app",
+			expected: "default/ing2",
+		},
+		// 9
+		{
+			paths: []path{
+				{"d1.local", "/", hatypes.MatchBegin, "default/ing1"},
+				{"*.d1.local", "/", hatypes.MatchBegin, "default/ing2"},
+				{"*", "/", hatypes.MatchBegin, "default/ing3"},
+			},
+			hostname: "d2.local",
+			request:  "/app",
+			expected: "default/ing3",
+		},
+		// 10
+		{
+			paths: []path{
+				{"d1.local", "/app", hatypes.MatchBegin, "default/ing1"},
+				{"d2.local", "/", hatypes.MatchBegin, "default/ing2"},
+			},
+			hostname: "d2.local",
+			request:  "/app",
+			expected: "default/ing2",
+		},
+		// 11
+		{
+			paths: []path{
+				{"d1.local", "/", hatypes.MatchBegin, "default/ing1"},
+			},
+			hostname: "alias.local",
+			request:  "/app",
+			expected: "default/ing1",
+		},
+	}
+	for i, test := range testCases {
+		c := createConfig(options{})
+		b := c.Backends().AcquireBackend("default", "app", "8080")
+		for _, p := range test.paths {
+			h := c.Hosts().AcquireHost(p.hostname)
+			h.AddPathMatch(b, p.path, p.match).Ingress = p.ingress
+		}
+		routes := buildLogRoutes(c.Hosts(), c.Backends())
+		backend := routes[b.ID]
+		if backend == nil {
+			t.Errorf("missing backend '%s' on %d", b.ID, i)
+			continue
+		}
+		if backend.namespace != "default" || backend.service != "app" {
+			t.Errorf("backend differs on %d -- expected: default/app -- actual: %s/%s", i, backend.namespace, backend.service)
+		}
+		actual := backend.findIngress(test.hostname, test.request)
+		if actual != test.expected {
+			t.Errorf("ingress differs on %d -- expected: '%s' -- actual: '%s'", i, test.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2020 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package haproxy
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	hautils "github.com/jcmoraisjr/haproxy-ingress/pkg/haproxy/utils"
+	"github.com/jcmoraisjr/haproxy-ingress/pkg/types"
+)
+
+// ProcessManager abstracts how the HAProxy process is managed: how a
+// configuration file is validated, and how HAProxy is started or reloaded.
+type ProcessManager interface {
+	// Check validates a configuration file.
+	Check(configFile string) error
+	// Reload starts HAProxy with the configuration file if it isn't
+	// running yet, or reloads it otherwise.
+	Reload(configFile string) error
+}
+
+// ExecOptions ...
+type ExecOptions struct {
+	// HAProxyCmd is used to validate the configuration, check is
+	// skipped if empty.
+	HAProxyCmd string
+	// ReloadCmd starts or reloads HAProxy using ReloadStrategy, reload
+	// is skipped if empty.
+	ReloadCmd      string
+	ReloadStrategy string
+	// AdminSocket and StateFile are sent to ReloadCmd, via HAPROXY_SOCKET
+	// and HAPROXY_STATE envvars, and are used to save the state of the
+	// servers before reloading.
+	AdminSocket string
+	StateFile   string
+}
+
+// NewExecProcessManager creates a ProcessManager that starts and reloads
+// HAProxy via an external reload command, eg haproxy-reload.sh.
+func NewExecProcessManager(logger types.Logger, options ExecOptions) ProcessManager {
+	return &execProcessManager{
+		logger:  logger,
+		options: options,
+	}
+}
+
+// SidecarOptions ...
+type SidecarOptions struct {
+	// HAProxyCmd is used to validate the configuration, check is
+	// skipped if empty.
+	HAProxyCmd string
+	// AdminSocket and StateFile are used to save the state of the
+	// servers before reloading.
+	AdminSocket string
+	StateFile   string
+	// MasterSocket is the HAProxy's master CLI socket. HAProxy is
+	// reloaded via MasterSocket if declared, otherwise the process read
+	// from PidFile is signaled.
+	MasterSocket string
+	PidFile      string
+}
+
+// NewSidecarProcessManager creates a ProcessManager that reloads a HAProxy
+// running in master-worker mode in another container of the same pod.
+// HAProxy is reloaded either via its master CLI socket, which only need
+// a volume shared between containers, or via a SIGUSR2 signal sent to the
+// master process, which also needs a PID namespace shared between containers.
+func NewSidecarProcessManager(logger types.Logger, options SidecarOptions) ProcessManager {
+	return &sidecarProcessManager{
+		logger:  logger,
+		options: options,
+	}
+}
+
+type execProcessManager struct {
+	logger  types.Logger
+	options ExecOptions
+}
+
+type sidecarProcessManager struct {
+	logger  types.Logger
+	options SidecarOptions
+}
+
+func (p *execProcessManager) Check(configFile string) error {
+	return checkConfig(p.logger, p.options.HAProxyCmd, configFile)
+}
+
+func (p *execProcessManager) Reload(configFile string) error {
+	if p.options.ReloadCmd == "" {
+		p.logger.Info("(test) reload was skipped")
+		return nil
+	}
+	cmd := exec.Command(p.options.ReloadCmd, p.options.ReloadStrategy, configFile)
+	cmd.Env = append(os.Environ(),
+		"HAPROXY_SOCKET="+p.options.AdminSocket,
+		"HAPROXY_STATE="+p.options.StateFile,
+	)
+	out, err := cmd.CombinedOutput()
+	outstr := string(out)
+	if len(outstr) > 0 {
+		p.logger.Warn("output from haproxy:\n%v", outstr)
+	}
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (p *sidecarProcessManager) Check(configFile string) error {
+	return checkConfig(p.logger, p.options.HAProxyCmd, configFile)
+}
+
+func (p *sidecarProcessManager) Reload(configFile string) error {
+	if p.options.MasterSocket != "" {
+		if _, err := os.Stat(p.options.MasterSocket); os.IsNotExist(err) {
+			p.logger.Info("HAProxy master socket not found, sidecar should read %s on start", configFile)
+			return nil
+		}
+		p.saveServersState()
+		_, err := hautils.HAProxyCommandOutput(p.options.MasterSocket, func(time.Duration) {}, "reload")
+		return err
+	}
+	pidstr, err := ioutil.ReadFile(p.options.PidFile)
+	if os.IsNotExist(err) {
+		p.logger.Info("HAProxy pid file not found, sidecar should read %s on start", configFile)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(pidstr)))
+	if err != nil {
+		return fmt.Errorf("invalid pid file %s: %v", p.options.PidFile, err)
+	}
+	p.saveServersState()
+	return signalReload(pid)
+}
+
+// saveServersState mimics haproxy-reload.sh: the state of the servers is
+// loaded by the new HAProxy process via server-state-file.
+func (p *sidecarProcessManager) saveServersState() {
+	state := "#\n"
+	if _, err := os.Stat(p.options.AdminSocket); err == nil {
+		out, err := hautils.HAProxyCommandOutput(p.options.AdminSocket, func(time.Duration) {}, "show servers state")
+		if err != nil {
+			p.logger.Warn("error reading servers state: %v", err)
+		} else {
+			state = out
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(p.options.StateFile), 0755); err != nil {
+		p.logger.Warn("error creating servers state dir: %v", err)
+		return
+	}
+	if err := ioutil.WriteFile(p.options.StateFile, []byte(state), 0644); err != nil {
+		p.logger.Warn("error writing servers state: %v", err)
+	}
+}
+
+func checkConfig(logger types.Logger, haproxyCmd, configFile string) error {
+	if haproxyCmd == "" {
+		logger.Info("(test) check was skipped")
+		return nil
+	}
+	args := []string{"-c", "-f", configFile}
+	if podName := os.Getenv("POD_NAME"); podName != "" {
+		// the pod name is the local peer name, see haproxy-reload.sh
+		args = append(args, "-L", podName)
+	}
+	out, err := exec.Command(haproxyCmd, args...).CombinedOutput()
+	outstr := string(out)
+	if err != nil {
+		return fmt.Errorf(outstr)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2020 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package haproxy
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jcmoraisjr/haproxy-ingress/pkg/types/helper_test"
+)
+
+func TestSidecarReloadNotRunning(t *testing.T) {
+	dir, err := ioutil.TempDir("", "haproxy-process")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	logger := &helper_test.LoggerMock{T: t}
+	testCases := []struct {
+		options SidecarOptions
+		logging string
+	}{
+		// 0
+		{
+			options: SidecarOptions{PidFile: filepath.Join(dir, "haproxy.pid")},
+			logging: "INFO HAProxy pid file not found, sidecar should read /etc/haproxy/haproxy.cfg on start",
+		},
+		// 1
+		{
+			options: SidecarOptions{MasterSocket: filepath.Join(dir, "master.sock")},
+			logging: "INFO HAProxy master socket not found, sidecar should read /etc/haproxy/haproxy.cfg on start",
+		},
+	}
+	for i, test := range testCases {
+		p := NewSidecarProcessManager(logger, test.options)
+		if err := p.Reload("/etc/haproxy/haproxy.cfg"); err != nil {
+			t.Errorf("unexpected error on %d: %v", i, err)
+		}
+		logger.CompareLogging(test.logging)
+	}
+}
+
+func TestSidecarReloadInvalidPid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "haproxy-process")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	pidFile := filepath.Join(dir, "haproxy.pid")
+	if err := ioutil.WriteFile(pidFile, []byte("none\n"), 0644); err != nil {
+		t.Fatalf("error writing pid file: %v", err)
+	}
+	logger := &helper_test.LoggerMock{T: t}
+	p := NewSidecarProcessManager(logger, SidecarOptions{PidFile: pidFile})
+	err = p.Reload("/etc/haproxy/haproxy.cfg")
+	if err == nil || !strings.HasPrefix(err.Error(), "invalid pid file") {
+		t.Errorf("expected invalid pid file error, actual: %v", err)
+	}
+	logger.CompareLogging("")
+}
+
+func TestSidecarReloadMasterSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "haproxy-process")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	masterSocket := filepath.Join(dir, "master.sock")
+	l, err := net.Listen("unix", masterSocket)
+	if err != nil {
+		t.Fatalf("error listening to master socket: %v", err)
+	}
+	defer l.Close()
+	cmd := make(chan string, 1)
+	go func() {
+		c, err := l.Accept()
+		if err != nil {
+			cmd <- err.Error()
+			return
+		}
+		line, _ := bufio.NewReader(c).ReadString('\n')
+		cmd <- strings.TrimSpace(line)
+		c.Close()
+	}()
+	stateFile := filepath.Join(dir, "state", "state-global")
+	logger := &helper_test.LoggerMock{T: t}
+	p := NewSidecarProcessManager(logger, SidecarOptions{
+		AdminSocket:  filepath.Join(dir, "admin.sock"),
+		StateFile:    stateFile,
+		MasterSocket: masterSocket,
+	})
+	if err := p.Reload("/etc/haproxy/haproxy.cfg"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if actual := <-cmd; actual != "reload" {
+		t.Errorf("expected 'reload' command, actual: '%s'", actual)
+	}
+	// admin socket isn't running, an empty state is expected
+	state, _ := ioutil.ReadFile(stateFile)
+	if string(state) != "#\n" {
+		t.Errorf("expected empty servers state, actual: '%s'", string(state))
+	}
+	logger.CompareLogging("")
+}
+
+func TestExecReloadEnv(t *testing.T) {
+	dir, err := ioutil.TempDir("", "haproxy-process")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	outFile := filepath.Join(dir, "out")
+	reloadCmd := filepath.Join(dir, "reload.sh")
+	script := "#!/bin/sh\necho \"$1 $2 $HAPROXY_SOCKET $HAPROXY_STATE\" > " + outFile + "\n"
+	if err := ioutil.WriteFile(reloadCmd, []byte(script), 0755); err != nil {
+		t.Fatalf("error writing reload script: %v", err)
+	}
+	logger := &helper_test.LoggerMock{T: t}
+	p := NewExecProcessManager(logger, ExecOptions{
+		ReloadCmd:      reloadCmd,
+		ReloadStrategy: "reusesocket",
+		AdminSocket:    "/var/run/admin.sock",
+		StateFile:      "/var/lib/haproxy/state",
+	})
+	if err := p.Reload("/etc/haproxy/haproxy.cfg"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	expected := "reusesocket /etc/haproxy/haproxy.cfg /var/run/admin.sock /var/lib/haproxy/state\n"
+	if out, _ := ioutil.ReadFile(outFile); string(out) != expected {
+		t.Errorf("reload args differ -- expected: '%s' -- actual: '%s'", expected, string(out))
+	}
+	logger.CompareLogging("")
+}
@@ -0,0 +1,29 @@
+//go:build windows
+// +build windows
+
+/*
+Copyright 2020 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package haproxy
+
+import (
+	"fmt"
+)
+
+// signalReload isn't supported on Windows, HAProxy master CLI should be used instead
+func signalReload(pid int) error {
+	return fmt.Errorf("cannot signal pid %d: signals aren't supported on windows, configure a master socket instead", pid)
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2020 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package haproxy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	hautils "github.com/jcmoraisjr/haproxy-ingress/pkg/haproxy/utils"
+)
+
+type tcpServiceStats struct {
+	sessions int64
+	bytesIn  int64
+	bytesOut int64
+}
+
+func (i *instance) CalcTCPServicesMetric() {
+	// the lock is held while reading the stats, so a reset made after a
+	// reload waits for a reading of the old process to finish
+	i.tcpStatsMutex.Lock()
+	defer i.tcpStatsMutex.Unlock()
+	config := i.runningConfig()
+	if config == nil || len(config.TCPBackends()) == 0 {
+		return
+	}
+	// -1 1 -1 == all proxies, frontends only, all servers
+	out, err := hautils.HAProxyCommandOutput(config.Global().AdminSocket, i.metrics.HAProxyShowStatResponseTime, "show stat -1 1 -1")
+	if err != nil {
+		i.logger.Error("error reading admin socket: %v", err)
+		return
+	}
+	stats, err := parseFrontendStats(out)
+	if err != nil {
+		i.logger.Error("error parsing show stat response: %v", err)
+		return
+	}
+	if i.tcpStats == nil {
+		i.tcpStats = make(map[string]tcpServiceStats, len(config.TCPBackends()))
+	}
+	for _, backend := range config.TCPBackends() {
+		proxy := fmt.Sprintf("_tcp_%s_%d", backend.Name, backend.Port)
+		cur, found := stats[proxy]
+		if !found {
+			continue
+		}
+		delta := cur.delta(i.tcpStats[proxy])
+		i.metrics.AddTCPServiceStats(backend.Name, backend.Port, delta.sessions, delta.bytesIn, delta.bytesOut)
+		i.tcpStats[proxy] = cur
+	}
+}
+
+// resetTCPServicesMetric should be called whenever HAProxy is reloaded,
+// counters of the new process start from zero.
+func (i *instance) resetTCPServicesMetric() {
+	i.tcpStatsMutex.Lock()
+	defer i.tcpStatsMutex.Unlock()
+	i.tcpStats = nil
+}
+
+// delta returns how much the counters increased since the last reading.
+// A counter lower than the last reading means that the counter was reset.
+func (s tcpServiceStats) delta(last tcpServiceStats) tcpServiceStats {
+	diff := func(cur, last int64) int64 {
+		if cur < last {
+			return cur
+		}
+		return cur - last
+	}
+	return tcpServiceStats{
+		sessions: diff(s.sessions, last.sessions),
+		bytesIn:  diff(s.bytesIn, last.bytesIn),
+		bytesOut: diff(s.bytesOut, last.bytesOut),
+	}
+}
+
+// parseFrontendStats reads the CSV response of a `show stat` command and
+// returns the counters of all the frontends, indexed by the proxy name.
+func parseFrontendStats(out string) (map[string]tcpServiceStats, error) {
+	counters, err := parseStats(out, "FRONTEND", "stot", "bin", "bout")
+	if err != nil {
+		return nil, err
+	}
+	stats := make(map[string]tcpServiceStats, len(counters))
+	for proxy, c := range counters {
+		stats[proxy] = tcpServiceStats{
+			sessions: c[0],
+			bytesIn:  c[1],
+			bytesOut: c[2],
+		}
+	}
+	return stats, nil
+}
+
+// parseStats reads the CSV response of a `show stat` command and returns
+// the counters of the rows whose svname matches, indexed by the proxy name.
+// Counters are returned in the same order of the fields argument.
+func parseStats(out, svname string, fields ...string) (map[string][]int64, error) {
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if !strings.HasPrefix(lines[0], "# ") {
+		return nil, fmt.Errorf("missing header")
+	}
+	header := make(map[string]int)
+	for i, field := range strings.Split(strings.TrimPrefix(lines[0], "# "), ",") {
+		header[field] = i
+	}
+	for _, field := range append([]string{"pxname", "svname"}, fields...) {
+		if _, found := header[field]; !found {
+			return nil, fmt.Errorf("missing field '%s'", field)
+		}
+	}
+	stats := make(map[string][]int64, len(lines)-1)
+	for _, line := range lines[1:] {
+		values := strings.Split(line, ",")
+		if len(values) < len(header) || values[header["svname"]] != svname {
+			continue
+		}
+		proxy := values[header["pxname"]]
+		counters := make([]int64, len(fields))
+		for i, field := range fields {
+			var err error
+			counters[i], err = strconv.ParseInt(values[header[field]], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid counter of '%s': %v", proxy, err)
+			}
+		}
+		stats[proxy] = counters
+	}
+	return stats, nil
+}
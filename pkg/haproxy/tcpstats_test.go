@@ -0,0 +1,157 @@
+/*
+Copyright 2020 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package haproxy
+
+import (
+	"bufio"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestParseFrontendStats(t *testing.T) {
+	testCases := []struct {
+		out      string
+		expected map[string]tcpServiceStats
+		err      string
+	}{
+		// 0
+		{
+			out: `
+# pxname,svname,qcur,qmax,scur,smax,slim,stot,bin,bout,dreq
+_tcp_default_pg_5432,FRONTEND,,,1,3,2000,15,2048,4096,0
+_front_http,FRONTEND,,,0,1,2000,2,100,200,0
+`,
+			expected: map[string]tcpServiceStats{
+				"_tcp_default_pg_5432": {sessions: 15, bytesIn: 2048, bytesOut: 4096},
+				"_front_http":          {sessions: 2, bytesIn: 100, bytesOut: 200},
+			},
+		},
+		// 1
+		{
+			out: `
+# pxname,svname,qcur,qmax,scur,smax,slim,stot,bin,bout
+_tcp_default_pg_5432,srv001,0,0,1,3,,15,2048,4096
+`,
+			expected: map[string]tcpServiceStats{},
+		},
+		// 2
+		{
+			out: `Unknown command.`,
+			err: "missing header",
+		},
+		// 3
+		{
+			out: `# pxname,svname,stot,bin`,
+			err: "missing field 'bout'",
+		},
+		// 4
+		{
+			out: `
+# pxname,svname,stot,bin,bout
+_tcp_default_pg_5432,FRONTEND,15,x,4096
+`,
+			err: `invalid counter of '_tcp_default_pg_5432': strconv.ParseInt: parsing "x": invalid syntax`,
+		},
+	}
+	for i, test := range testCases {
+		stats, err := parseFrontendStats(test.out)
+		var errstr string
+		if err != nil {
+			errstr = err.Error()
+		}
+		if errstr != test.err {
+			t.Errorf("error differs on %d -- expected: '%s' -- actual: '%s'", i, test.err, errstr)
+		}
+		if test.err == "" && !reflect.DeepEqual(stats, test.expected) {
+			t.Errorf("stats differs on %d -- expected: %+v -- actual: %+v", i, test.expected, stats)
+		}
+	}
+}
+
+func TestTCPServiceStatsDelta(t *testing.T) {
+	testCases := []struct {
+		last     tcpServiceStats
+		cur      tcpServiceStats
+		expected tcpServiceStats
+	}{
+		// 0
+		{
+			cur:      tcpServiceStats{sessions: 10, bytesIn: 100, bytesOut: 200},
+			expected: tcpServiceStats{sessions: 10, bytesIn: 100, bytesOut: 200},
+		},
+		// 1
+		{
+			last:     tcpServiceStats{sessions: 10, bytesIn: 100, bytesOut: 200},
+			cur:      tcpServiceStats{sessions: 12, bytesIn: 150, bytesOut: 200},
+			expected: tcpServiceStats{sessions: 2, bytesIn: 50, bytesOut: 0},
+		},
+		// 2
+		{
+			last:     tcpServiceStats{sessions: 10, bytesIn: 100, bytesOut: 200},
+			cur:      tcpServiceStats{sessions: 3, bytesIn: 30, bytesOut: 60},
+			expected: tcpServiceStats{sessions: 3, bytesIn: 30, bytesOut: 60},
+		},
+	}
+	for i, test := range testCases {
+		actual := test.cur.delta(test.last)
+		if actual != test.expected {
+			t.Errorf("delta differs on %d -- expected: %+v -- actual: %+v", i, test.expected, actual)
+		}
+	}
+}
+
+func TestCalcTCPServicesMetricUpdate(t *testing.T) {
+	c := setup(t)
+	defer c.teardown()
+	adminSocket := c.tempdir + "/admin.sock"
+	l, err := net.Listen("unix", adminSocket)
+	if err != nil {
+		t.Fatalf("error listening to admin socket: %v", err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = bufio.NewReader(conn).ReadString('\n')
+			_, _ = conn.Write([]byte("# pxname,svname,stot,bin,bout\n_tcp_pq_5432,FRONTEND,1,10,20\n"))
+			conn.Close()
+		}
+	}()
+	instance := c.instance.(*instance)
+	done := make(chan bool)
+	go func() {
+		// run with -race, stats are read while the sync replaces the config
+		for i := 0; i < 20; i++ {
+			instance.CalcTCPServicesMetric()
+		}
+		close(done)
+	}()
+	for i := 0; i < 10; i++ {
+		c.config.Global().AdminSocket = adminSocket
+		c.config.AcquireTCPBackend("pq", 5432+i%2)
+		c.Update()
+		c.config = c.newConfig()
+		instance.curConfig = c.config
+	}
+	<-done
+	// update logging is already covered by the instance tests
+	c.logger.Logging = []string{}
+}
@@ -114,6 +114,7 @@ func (b *Backend) AddHostPath(hostname, path string) *BackendPath {
 		Hostname: hostname,
 		Hostpath: hostpath,
 		Path:     path,
+		Match:    MatchBegin,
 	}
 	b.Paths = append(b.Paths, backendPath)
 	// reverse order in order to avoid overlap of sub-paths
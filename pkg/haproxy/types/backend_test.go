@@ -30,47 +30,47 @@ func TestAddPath(t *testing.T) {
 		{
 			input: []string{"/"},
 			expected: []*BackendPath{
-				{"path01", "d1.local", "d1.local/", "/"},
+				{"path01", "d1.local", "d1.local/", "/", MatchBegin},
 			},
 		},
 		// 1
 		{
 			input: []string{"/app", "/app"},
 			expected: []*BackendPath{
-				{"path01", "d1.local", "d1.local/app", "/app"},
+				{"path01", "d1.local", "d1.local/app", "/app", MatchBegin},
 			},
 		},
 		// 2
 		{
 			input: []string{"/app", "/root"},
 			expected: []*BackendPath{
-				{"path02", "d1.local", "d1.local/root", "/root"},
-				{"path01", "d1.local", "d1.local/app", "/app"},
+				{"path02", "d1.local", "d1.local/root", "/root", MatchBegin},
+				{"path01", "d1.local", "d1.local/app", "/app", MatchBegin},
 			},
 		},
 		// 3
 		{
 			input: []string{"/app", "/root", "/root"},
 			expected: []*BackendPath{
-				{"path02", "d1.local", "d1.local/root", "/root"},
-				{"path01", "d1.local", "d1.local/app", "/app"},
+				{"path02", "d1.local", "d1.local/root", "/root", MatchBegin},
+				{"path01", "d1.local", "d1.local/app", "/app", MatchBegin},
 			},
 		},
 		// 4
 		{
 			input: []string{"/app", "/root", "/app"},
 			expected: []*BackendPath{
-				{"path02", "d1.local", "d1.local/root", "/root"},
-				{"path01", "d1.local", "d1.local/app", "/app"},
+				{"path02", "d1.local", "d1.local/root", "/root", MatchBegin},
+				{"path01", "d1.local", "d1.local/app", "/app", MatchBegin},
 			},
 		},
 		// 5
 		{
 			input: []string{"/", "/app", "/root"},
 			expected: []*BackendPath{
-				{"path03", "d1.local", "d1.local/root", "/root"},
-				{"path02", "d1.local", "d1.local/app", "/app"},
-				{"path01", "d1.local", "d1.local/", "/"},
+				{"path03", "d1.local", "d1.local/root", "/root", MatchBegin},
+				{"path02", "d1.local", "d1.local/app", "/app", MatchBegin},
+				{"path01", "d1.local", "d1.local/", "/", MatchBegin},
 			},
 		},
 	}
@@ -24,6 +24,15 @@ import (
 
 // AppendHostname ...
 func (hm *HostsMap) AppendHostname(base, value string) {
+	hm.AppendHostnameMatch(base, MatchBegin, value)
+}
+
+// AppendHostnameMatch adds a hostname and an optional path to the HostsMap.
+// The match type is used if base has a path and defines how the path is
+// compared: begin adds a map_beg() key, exact adds a map() key to the exact
+// map and prefix adds both, one key for the path and another one for its
+// subpaths. Regex keys use end of line and path delimiter patterns instead.
+func (hm *HostsMap) AppendHostnameMatch(base string, match MatchType, value string) {
 	// always use case insensitive match
 	base = strings.ToLower(base)
 	isHostnameOnly := !strings.Contains(base, "/")
@@ -35,12 +44,14 @@ func (hm *HostsMap) AppendHostname(base, value string) {
 			// match eol if only the hostname is provided
 			// if has /path, need to match the begining of the string, a la map_beg() converter
 			key = key + "$"
+		} else {
+			key = regexMatch(key, match)
 		}
 		hm.Regex = append(hm.Regex, &HostsMapEntry{
 			Key:   key,
 			Value: value,
 		})
-	} else {
+	} else if isHostnameOnly {
 		// sub.example.local
 		hm.Match = append(hm.Match, &HostsMapEntry{
 			Key:   base,
@@ -48,41 +59,79 @@ func (hm *HostsMap) AppendHostname(base, value string) {
 		})
 		// Hostnames are already in alphabetical order but Alias are not
 		// Sort only hostname maps which uses ebtree search via map converter
-		if isHostnameOnly {
-			sort.Slice(hm.Match, func(i, j int) bool {
-				return hm.Match[i].Key < hm.Match[j].Key
+		sort.Slice(hm.Match, func(i, j int) bool {
+			return hm.Match[i].Key < hm.Match[j].Key
+		})
+	} else {
+		// sub.example.local/path
+		hm.appendPathMatch(base, match, value)
+	}
+}
+
+func (hm *HostsMap) appendPathMatch(key string, match MatchType, value string) {
+	switch match {
+	case MatchExact:
+		hm.Exact = append(hm.Exact, &HostsMapEntry{
+			Key:   key,
+			Value: value,
+		})
+	case MatchPrefix:
+		// `/app` matches `/app` and `/app/sub` but doesn't match `/application`.
+		// A trailing slash doesn't change the meaning, `/app/` is the same of `/app`
+		key = strings.TrimSuffix(key, "/")
+		if strings.Contains(key, "/") {
+			// the root path is the only one without the exact match
+			hm.Exact = append(hm.Exact, &HostsMapEntry{
+				Key:   key,
+				Value: value,
 			})
 		}
+		hm.Match = append(hm.Match, &HostsMapEntry{
+			Key:   key + "/",
+			Value: value,
+		})
+	default:
+		hm.Match = append(hm.Match, &HostsMapEntry{
+			Key:   key,
+			Value: value,
+		})
 	}
 }
 
+func regexMatch(key string, match MatchType) string {
+	switch match {
+	case MatchExact:
+		return key + "$"
+	case MatchPrefix:
+		return strings.TrimSuffix(key, "/") + "(/|$)"
+	}
+	return key
+}
+
 // AppendAliasName ...
-func (hm *HostsMap) AppendAliasName(base, value string) {
+func (hm *HostsMap) AppendAliasName(base string, match MatchType, value string) {
 	if base != "" {
-		hm.AppendHostname(base, value)
+		hm.AppendHostnameMatch(base, match, value)
 	}
 }
 
 // AppendAliasRegex ...
-func (hm *HostsMap) AppendAliasRegex(base, value string) {
+func (hm *HostsMap) AppendAliasRegex(base string, match MatchType, value string) {
 	// always use case insensitive match
 	base = strings.ToLower(base)
 	if base != "" {
 		hm.Regex = append(hm.Regex, &HostsMapEntry{
-			Key:   base,
+			Key:   regexMatch(base, match),
 			Value: value,
 		})
 	}
 }
 
 // AppendPath ...
-func (hm *HostsMap) AppendPath(path, id string) {
+func (hm *HostsMap) AppendPath(path string, match MatchType, id string) {
 	// always use case insensitive match
 	path = strings.ToLower(path)
-	hm.Match = append(hm.Match, &HostsMapEntry{
-		Key:   path,
-		Value: id,
-	})
+	hm.appendPathMatch(path, match, id)
 	sort.SliceStable(hm.Match, func(i, j int) bool {
 		return hm.Match[i].Key > hm.Match[j].Key
 	})
@@ -95,6 +144,11 @@ func (hm *HostsMap) AppendItem(item string) {
 	})
 }
 
+// HasExact ...
+func (hm *HostsMap) HasExact() bool {
+	return len(hm.Exact) > 0
+}
+
 // HasRegex ...
 func (hm *HostsMap) HasRegex() bool {
 	return len(hm.Regex) > 0
@@ -102,7 +156,7 @@ func (hm *HostsMap) HasRegex() bool {
 
 // HasHost ...
 func (hm *HostsMap) HasHost() bool {
-	return len(hm.Regex) > 0 || len(hm.Match) > 0
+	return len(hm.Regex) > 0 || len(hm.Exact) > 0 || len(hm.Match) > 0
 }
 
 // CreateMaps ...
@@ -113,9 +167,11 @@ func CreateMaps() *HostsMaps {
 // AddMap ...
 func (hm *HostsMaps) AddMap(filename string) *HostsMap {
 	matchFile := filename
+	exactFile := strings.Replace(filename, ".", "_exact.", 1)
 	regexFile := strings.Replace(filename, ".", "_regex.", 1)
 	hmap := &HostsMap{
 		MatchFile: matchFile,
+		ExactFile: exactFile,
 		RegexFile: regexFile,
 	}
 	hm.Items = append(hm.Items, hmap)
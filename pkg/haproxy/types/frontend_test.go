@@ -17,6 +17,7 @@ limitations under the License.
 package types
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -62,3 +63,54 @@ func TestAppendHostname(t *testing.T) {
 		}
 	}
 }
+
+func TestAppendHostnameMatch(t *testing.T) {
+	testCases := []struct {
+		base          string
+		match         MatchType
+		expectedExact []string
+		expectedMatch []string
+		expectedRegex []string
+	}{
+		// 0
+		{base: "example.local/app", match: MatchBegin, expectedMatch: []string{"example.local/app"}},
+		// 1
+		{base: "example.local/app", match: MatchExact, expectedExact: []string{"example.local/app"}},
+		// 2
+		{base: "example.local/app", match: MatchPrefix, expectedExact: []string{"example.local/app"}, expectedMatch: []string{"example.local/app/"}},
+		// 3
+		{base: "example.local/app/", match: MatchPrefix, expectedExact: []string{"example.local/app"}, expectedMatch: []string{"example.local/app/"}},
+		// 4
+		{base: "example.local/", match: MatchPrefix, expectedMatch: []string{"example.local/"}},
+		// 5
+		{base: "example.local/", match: MatchExact, expectedExact: []string{"example.local/"}},
+		// 6
+		{base: "*.example.local/app", match: MatchBegin, expectedRegex: []string{"^[^.]+\\.example\\.local/app"}},
+		// 7
+		{base: "*.example.local/app", match: MatchExact, expectedRegex: []string{"^[^.]+\\.example\\.local/app$"}},
+		// 8
+		{base: "*.example.local/app/", match: MatchPrefix, expectedRegex: []string{"^[^.]+\\.example\\.local/app(/|$)"}},
+		// 9
+		{base: "*.example.local/", match: MatchPrefix, expectedRegex: []string{"^[^.]+\\.example\\.local(/|$)"}},
+	}
+	keys := func(entries []*HostsMapEntry) []string {
+		var k []string
+		for _, e := range entries {
+			k = append(k, e.Key)
+		}
+		return k
+	}
+	for i, test := range testCases {
+		hm := &HostsMap{}
+		hm.AppendHostnameMatch(test.base, test.match, "backend")
+		if exact := keys(hm.Exact); !reflect.DeepEqual(exact, test.expectedExact) {
+			t.Errorf("item %d, expected exact keys %v, but was %v", i, test.expectedExact, exact)
+		}
+		if match := keys(hm.Match); !reflect.DeepEqual(match, test.expectedMatch) {
+			t.Errorf("item %d, expected match keys %v, but was %v", i, test.expectedMatch, match)
+		}
+		if regex := keys(hm.Regex); !reflect.DeepEqual(regex, test.expectedRegex) {
+			t.Errorf("item %d, expected regex keys %v, but was %v", i, test.expectedRegex, regex)
+		}
+	}
+}
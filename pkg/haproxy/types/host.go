@@ -127,6 +127,11 @@ func (h *Host) FindPath(path string) *HostPath {
 
 // AddPath ...
 func (h *Host) AddPath(backend *Backend, path string) {
+	h.AddPathMatch(backend, path, MatchBegin)
+}
+
+// AddPathMatch ...
+func (h *Host) AddPathMatch(backend *Backend, path string, match MatchType) *HostPath {
 	var hback HostBackend
 	if backend != nil {
 		hback = HostBackend{
@@ -135,18 +140,27 @@ func (h *Host) AddPath(backend *Backend, path string) {
 			Name:      backend.Name,
 			Port:      backend.Port,
 		}
-		backend.AddHostPath(h.Hostname, path)
+		backend.AddHostPath(h.Hostname, path).Match = match
 	} else {
 		hback = HostBackend{ID: "_error404"}
 	}
-	h.Paths = append(h.Paths, &HostPath{
+	hpath := &HostPath{
 		Path:    path,
+		Match:   match,
 		Backend: hback,
-	})
-	// reverse order in order to avoid overlap of sub-paths
+	}
+	h.Paths = append(h.Paths, hpath)
+	// exact match first, remaining paths in reverse order
+	// in order to avoid overlap of sub-paths
 	sort.Slice(h.Paths, func(i, j int) bool {
-		return h.Paths[i].Path > h.Paths[j].Path
+		p1 := h.Paths[i]
+		p2 := h.Paths[j]
+		if (p1.Match == MatchExact) != (p2.Match == MatchExact) {
+			return p1.Match == MatchExact
+		}
+		return p1.Path > p2.Path
 	})
+	return hpath
 }
 
 // HasTLSAuth ...
@@ -52,8 +52,11 @@ type Global struct {
 	ForwardFor      string
 	LoadServerState bool
 	AdminSocket     string
+	StateFile       string
 	Healthz         HealthzConfig
+	Peers           PeersConfig
 	Prometheus      PromConfig
+	RequestID       RequestIDConfig
 	Stats           StatsConfig
 	StrictHost      bool
 	UseChroot       bool
@@ -93,6 +96,7 @@ type SyslogConfig struct {
 	HTTPLogFormat  string
 	HTTPSLogFormat string
 	Length         int
+	MetricsSocket  string
 	Tag            string
 	TCPLogFormat   string
 }
@@ -140,6 +144,7 @@ type DNSResolver struct {
 	AcceptedPayloadSize int
 	HoldObsolete        string
 	HoldValid           string
+	ParseResolvConf     bool
 	TimeoutRetry        string
 }
 
@@ -172,12 +177,32 @@ type HealthzConfig struct {
 	Port   int
 }
 
+// PeersConfig ...
+type PeersConfig struct {
+	LocalPeer string
+	Servers   []*PeerServer
+}
+
+// PeerServer ...
+type PeerServer struct {
+	Name string
+	IP   string
+	Port int
+}
+
 // PromConfig ...
 type PromConfig struct {
 	BindIP string
 	Port   int
 }
 
+// RequestIDConfig ...
+type RequestIDConfig struct {
+	Enabled bool
+	Format  string
+	Header  string
+}
+
 // StatsConfig ...
 type StatsConfig struct {
 	AcceptProxy bool
@@ -205,6 +230,7 @@ type TCPBackend struct {
 	Port          int
 	Endpoints     []*TCPEndpoint
 	CheckInterval string
+	LogFormat     string
 	SSL           TCPSSL
 	ProxyProt     TCPProxyProt
 }
@@ -240,6 +266,8 @@ type HostsMapEntry struct {
 type HostsMap struct {
 	Match     []*HostsMapEntry
 	MatchFile string
+	Exact     []*HostsMapEntry
+	ExactFile string
 	Regex     []*HostsMapEntry
 	RegexFile string
 }
@@ -316,9 +344,26 @@ type Host struct {
 // empty, a default 404 page generated by HAProxy will be used.
 type HostPath struct {
 	Path    string
+	Match   MatchType
 	Backend HostBackend
+	Ingress string
 }
 
+// MatchType defines how the path of a request is compared
+// with the path declared in a HostPath or a BackendPath
+type MatchType string
+
+// ...
+const (
+	// MatchBegin matches any request path starting with the declared path
+	MatchBegin = MatchType("begin")
+	// MatchExact matches only if the request path is the declared path
+	MatchExact = MatchType("exact")
+	// MatchPrefix matches the declared path and all of its subpaths,
+	// using the slash as the path element delimiter
+	MatchPrefix = MatchType("prefix")
+)
+
 // HostBackend ...
 type HostBackend struct {
 	ID        string
@@ -369,17 +414,19 @@ type Backend struct {
 	//
 	// core config
 	//
-	ID        string
-	Namespace string
-	Name      string
-	Port      string
-	Endpoints []*Endpoint
-	EpNaming  EndpointNaming
-	Paths     []*BackendPath
-	PathsMap  *HostsMap
+	ID           string
+	Namespace    string
+	Name         string
+	Port         string
+	Endpoints    []*Endpoint
+	EpNaming     EndpointNaming
+	ExternalName string
+	Paths        []*BackendPath
+	PathsMap     *HostsMap
 	//
 	// per backend config
 	//
+	AccessLog        BackendAccessLogConfig
 	AgentCheck       AgentCheck
 	BalanceAlgorithm string
 	BlueGreen        BlueGreenConfig
@@ -391,10 +438,12 @@ type Backend struct {
 	Limit            BackendLimit
 	ModeTCP          bool
 	OAuth            OAuthConfig
+	RequestID        BackendRequestIDConfig
 	Resolver         string
 	Server           ServerConfig
 	Timeout          BackendTimeoutConfig
 	TLS              BackendTLSConfig
+	WakeUp           WakeUpConfig
 	WhitelistTCP     []string
 	//
 	// per path config
@@ -430,13 +479,16 @@ type Backend struct {
 
 // Endpoint ...
 type Endpoint struct {
+	Backup    bool
 	Enabled   bool
 	Label     string
 	IP        string
 	Name      string
+	NotReady  bool
 	Port      int
 	Target    string
 	TargetRef string
+	UID       string
 	Weight    int
 }
 
@@ -457,6 +509,7 @@ type BackendPath struct {
 	Hostname string
 	Hostpath string
 	Path     string
+	Match    MatchType
 }
 
 // BackendHeader ...
@@ -573,6 +626,19 @@ type ServerConfig struct {
 	SendProxy     string
 }
 
+// BackendAccessLogConfig ...
+type BackendAccessLogConfig struct {
+	Disabled   bool
+	OnlyErrors bool
+	Sample     int
+}
+
+// BackendRequestIDConfig ...
+type BackendRequestIDConfig struct {
+	Forward  bool
+	Response bool
+}
+
 // BackendTimeoutConfig ...
 type BackendTimeoutConfig struct {
 	Connect     string
@@ -591,6 +657,14 @@ type BackendTLSConfig struct {
 	HasTLSAuth       bool
 }
 
+// WakeUpConfig ...
+type WakeUpConfig struct {
+	Deployment string
+	// Timeout in milliseconds
+	Timeout int
+	Webhook string
+}
+
 // UserlistConfig ...
 type UserlistConfig struct {
 	Name  string
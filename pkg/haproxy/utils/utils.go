@@ -18,6 +18,7 @@ package utils
 
 import (
 	"fmt"
+	"io/ioutil"
 	"net"
 	"time"
 )
@@ -48,3 +49,27 @@ func HAProxyCommand(socket string, observer func(duration time.Duration), comman
 	}
 	return msg, nil
 }
+
+// HAProxyCommandOutput sends a single command to the admin socket and
+// returns its whole response. Use it on commands whose response might
+// not fit in the buffer used by HAProxyCommand, like `show stat`.
+func HAProxyCommandOutput(socket string, observer func(duration time.Duration), command string) (string, error) {
+	start := time.Now()
+	c, err := net.Dial("unix", socket)
+	if err != nil {
+		return "", fmt.Errorf("error connecting to unix socket %s: %v", socket, err)
+	}
+	defer c.Close()
+	command = command + "\n"
+	if sent, err := c.Write([]byte(command)); err != nil {
+		return "", fmt.Errorf("error sending to unix socket %s: %v", socket, err)
+	} else if sent != len(command) {
+		return "", fmt.Errorf("incomplete data sent to unix socket %s", socket)
+	}
+	out, err := ioutil.ReadAll(c)
+	if err != nil {
+		return "", fmt.Errorf("error reading response buffer: %v", err)
+	}
+	observer(time.Since(start))
+	return string(out), nil
+}
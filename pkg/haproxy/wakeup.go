@@ -0,0 +1,68 @@
+/*
+Copyright 2020 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package haproxy
+
+import (
+	hatypes "github.com/jcmoraisjr/haproxy-ingress/pkg/haproxy/types"
+	hautils "github.com/jcmoraisjr/haproxy-ingress/pkg/haproxy/utils"
+)
+
+// WakeUpBackends returns the backends configured to be woken up that
+// don't have any endpoint and have at least one request waiting for it.
+func (i *instance) WakeUpBackends() []*hatypes.Backend {
+	config := i.runningConfig()
+	if config == nil {
+		return nil
+	}
+	var sleeping []*hatypes.Backend
+	for _, backend := range config.Backends().Items() {
+		// backends resolved via DNS don't track the number of endpoints
+		if backend.WakeUp.Timeout > 0 && backend.Resolver == "" && !hasActiveEndpoint(backend) {
+			sleeping = append(sleeping, backend)
+		}
+	}
+	if len(sleeping) == 0 {
+		return nil
+	}
+	// -1 2 -1 == all proxies, backends only, all servers
+	out, err := hautils.HAProxyCommandOutput(config.Global().AdminSocket, i.metrics.HAProxyShowStatResponseTime, "show stat -1 2 -1")
+	if err != nil {
+		i.logger.Error("error reading admin socket: %v", err)
+		return nil
+	}
+	stats, err := parseStats(out, "BACKEND", "scur")
+	if err != nil {
+		i.logger.Error("error parsing show stat response: %v", err)
+		return nil
+	}
+	var waiting []*hatypes.Backend
+	for _, backend := range sleeping {
+		if scur, found := stats[backend.ID]; found && scur[0] > 0 {
+			waiting = append(waiting, backend)
+		}
+	}
+	return waiting
+}
+
+func hasActiveEndpoint(backend *hatypes.Backend) bool {
+	for _, ep := range backend.Endpoints {
+		if !ep.IsEmpty() && ep.Weight > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2020 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package haproxy
+
+import (
+	"reflect"
+	"testing"
+
+	hatypes "github.com/jcmoraisjr/haproxy-ingress/pkg/haproxy/types"
+)
+
+func TestParseBackendSessions(t *testing.T) {
+	out := `
+# pxname,svname,qcur,qmax,scur,smax,slim,stot
+default_app_8080,srv001,0,0,0,0,,0
+default_app_8080,BACKEND,0,0,2,2,200,2
+default_echo_8080,BACKEND,0,0,0,1,200,10
+_front_http,FRONTEND,,,2,3,2000,12
+`
+	stats, err := parseStats(out, "BACKEND", "scur")
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	expected := map[string][]int64{
+		"default_app_8080":  {2},
+		"default_echo_8080": {0},
+	}
+	if !reflect.DeepEqual(stats, expected) {
+		t.Errorf("stats differ -- expected: %v -- actual: %v", expected, stats)
+	}
+}
+
+func TestHasActiveEndpoint(t *testing.T) {
+	testCases := []struct {
+		endpoints []*hatypes.Endpoint
+		expected  bool
+	}{
+		// 0
+		{
+			endpoints: nil,
+			expected:  false,
+		},
+		// 1
+		{
+			endpoints: []*hatypes.Endpoint{
+				{IP: "127.0.0.1", Port: 1023, Weight: 0},
+			},
+			expected: false,
+		},
+		// 2
+		{
+			endpoints: []*hatypes.Endpoint{
+				{IP: "172.17.0.11", Port: 8080, Weight: 0},
+			},
+			expected: false,
+		},
+		// 3
+		{
+			endpoints: []*hatypes.Endpoint{
+				{IP: "127.0.0.1", Port: 1023, Weight: 0},
+				{IP: "172.17.0.11", Port: 8080, Weight: 100},
+			},
+			expected: true,
+		},
+	}
+	for i, test := range testCases {
+		backend := &hatypes.Backend{Endpoints: test.endpoints}
+		if actual := hasActiveEndpoint(backend); actual != test.expected {
+			t.Errorf("active endpoint differs on %d -- expected: %v -- actual: %v", i, test.expected, actual)
+		}
+	}
+}
+
+func TestWakeUpBackendsUpdate(t *testing.T) {
+	c := setup(t)
+	defer c.teardown()
+	instance := c.instance.(*instance)
+	done := make(chan bool)
+	go func() {
+		// run with -race, backends are read while the sync replaces the config
+		for i := 0; i < 20; i++ {
+			if waiting := instance.WakeUpBackends(); waiting != nil {
+				t.Errorf("expected no backend waiting, actual: %v", waiting)
+			}
+		}
+		close(done)
+	}()
+	for i := 0; i < 10; i++ {
+		b := c.config.Backends().AcquireBackend("default", "app", "8080")
+		b.AcquireEndpoint("172.17.0.11", 8080+i%2, "")
+		b.WakeUp.Timeout = 10000
+		c.Update()
+		c.config = c.newConfig()
+		instance.curConfig = c.config
+	}
+	<-done
+	// update logging is already covered by the instance tests
+	c.logger.Logging = []string{}
+}
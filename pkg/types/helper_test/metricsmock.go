@@ -40,6 +40,10 @@ func (m *MetricsMock) HAProxyShowInfoResponseTime(duration time.Duration) {
 func (m *MetricsMock) HAProxySetServerResponseTime(duration time.Duration) {
 }
 
+// HAProxyShowStatResponseTime ...
+func (m *MetricsMock) HAProxyShowStatResponseTime(duration time.Duration) {
+}
+
 // ControllerProcTime ...
 func (m *MetricsMock) ControllerProcTime(task string, duration time.Duration) {
 
@@ -49,6 +53,10 @@ func (m *MetricsMock) ControllerProcTime(task string, duration time.Duration) {
 func (m *MetricsMock) AddIdleFactor(idle int) {
 }
 
+// AddTCPServiceStats ...
+func (m *MetricsMock) AddTCPServiceStats(service string, port int, sessions, bytesIn, bytesOut int64) {
+}
+
 // IncUpdateNoop ...
 func (m *MetricsMock) IncUpdateNoop() {
 }
@@ -80,3 +88,11 @@ func (m *MetricsMock) IncCertSigningExpiring(domains string, success bool) {
 // IncCertSigningOutdated ...
 func (m *MetricsMock) IncCertSigningOutdated(domains string, success bool) {
 }
+
+// SetConfigHash ...
+func (m *MetricsMock) SetConfigHash(hash string) {
+}
+
+// ObserveBackendRequest ...
+func (m *MetricsMock) ObserveBackendRequest(namespace, ingress, service, backend string, status int, duration time.Duration) {
+}
@@ -24,8 +24,10 @@ import (
 type Metrics interface {
 	HAProxyShowInfoResponseTime(duration time.Duration)
 	HAProxySetServerResponseTime(duration time.Duration)
+	HAProxyShowStatResponseTime(duration time.Duration)
 	ControllerProcTime(task string, duration time.Duration)
 	AddIdleFactor(idle int)
+	AddTCPServiceStats(service string, port int, sessions, bytesIn, bytesOut int64)
 	IncUpdateNoop()
 	IncUpdateDynamic()
 	IncUpdateFull()
@@ -34,4 +36,6 @@ type Metrics interface {
 	IncCertSigningMissing(domains string, success bool)
 	IncCertSigningExpiring(domains string, success bool)
 	IncCertSigningOutdated(domains string, success bool)
+	SetConfigHash(hash string)
+	ObserveBackendRequest(namespace, ingress, service, backend string, status int, duration time.Duration)
 }
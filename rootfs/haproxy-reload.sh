@@ -31,12 +31,14 @@
 #  -sf soft reload, wait for pids to finish handling requests
 #      send pids a resume signal if reload of new config fails
 #  -x get the listening sockets from the old HAProxy process
+#  -L local peer name, the pod name if POD_NAME envvar is declared
 
 set -e
 
-HAPROXY_SOCKET=/var/run/haproxy-stats.sock
-HAPROXY_STATE=/var/lib/haproxy/state-global
-mkdir -p /var/lib/haproxy
+HAPROXY_SOCKET=${HAPROXY_SOCKET:-/var/run/haproxy-stats.sock}
+HAPROXY_STATE=${HAPROXY_STATE:-/var/lib/haproxy/state-global}
+HAPROXY_LOCALPEER=${POD_NAME:+-L $POD_NAME}
+mkdir -p $(dirname "$HAPROXY_STATE")
 if [ -S $HAPROXY_SOCKET ]; then
     echo "show servers state" | socat $HAPROXY_SOCKET - > $HAPROXY_STATE
 else
@@ -46,7 +48,7 @@ case "$1" in
     native)
         CONFIG="$2"
         HAPROXY_PID=/var/run/haproxy.pid
-        haproxy -f "$CONFIG" -p "$HAPROXY_PID" -D -sf $(cat "$HAPROXY_PID" 2>/dev/null || :)
+        haproxy -f "$CONFIG" -p "$HAPROXY_PID" $HAPROXY_LOCALPEER -D -sf $(cat "$HAPROXY_PID" 2>/dev/null || :)
         ;;
     reusesocket|multibinder)
         # multibinder is now deprecated and, if used, is an alias to reusesocket
@@ -54,9 +56,9 @@ case "$1" in
         HAPROXY_PID=/var/run/haproxy.pid
         OLD_PID=$(cat "$HAPROXY_PID" 2>/dev/null || :)
         if [ -S "$HAPROXY_SOCKET" ]; then
-            haproxy -f "$CONFIG" -p "$HAPROXY_PID" -sf $OLD_PID -x "$HAPROXY_SOCKET"
+            haproxy -f "$CONFIG" -p "$HAPROXY_PID" $HAPROXY_LOCALPEER -sf $OLD_PID -x "$HAPROXY_SOCKET"
         else
-            haproxy -f "$CONFIG" -p "$HAPROXY_PID" -sf $OLD_PID
+            haproxy -f "$CONFIG" -p "$HAPROXY_PID" $HAPROXY_LOCALPEER -sf $OLD_PID
         fi
         ;;
     *)
@@ -79,3 +79,14 @@ A client certificate must be provided.
 </body></html>
 ]])
 end)
+
+-- Holds the request while the backend doesn't have any available server,
+-- up to `timeout` milliseconds. The controller reads the backends with
+-- waiting requests and wakes their workload up.
+core.register_action("wait-backend", { "http-req" }, function(txn, timeout)
+    local remaining = tonumber(timeout)
+    while txn.f:nbsrv() == 0 and remaining > 0 do
+        core.msleep(100)
+        remaining = remaining - 100
+    end
+end, 1)